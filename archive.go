@@ -0,0 +1,158 @@
+package copy
+
+import (
+	"io"
+	"path"
+	"path/filepath"
+)
+
+// fileID identifies a file on disk by device and inode.
+type fileID struct {
+	dev, ino uint64
+}
+
+// archiver writes the entries of a walked tree in an archive format.
+type archiver interface {
+	// writeEntry writes a single entry. Entries are written parents first
+	// and in lexical order within a directory.
+	writeEntry(e *archiveEntry) error
+	// close finishes the archive.
+	close() error
+}
+
+// archiveEntry is a single entry passed to an archiver.
+type archiveEntry struct {
+	obj copyObject
+	// name is the slash-separated name of the entry relative to the root,
+	// which is named "."
+	name string
+	// target is the destination of a symlink
+	target string
+	// linkname is set for a file with several hard links to the name it was
+	// first written under. The content is only written with the first name.
+	linkname string
+	o        *options
+}
+
+// writeContent copies the content of the regular file to w.
+func (e *archiveEntry) writeContent(w io.Writer) error {
+	sf, err := open(e.o.fsys, e.obj.Path())
+	if err != nil {
+		return &Error{Op: "Open", Src: e.obj.Path(), Dst: e.name, Err: err}
+	}
+
+	defer closeFile(sf)
+
+	if _, err = io.Copy(w, sf); err != nil {
+		return &Error{Op: "Copy", Src: e.obj.Path(), Dst: e.name, Err: err}
+	}
+
+	return nil
+}
+
+// writeArchive walks src with the same classification and options as All
+// and writes every entry to a.
+func writeArchive(src string, a archiver, o *options) error {
+	w := &archiveWalker{a: a, o: o, links: make(map[fileID]string)}
+
+	if err := w.add(src, "."); err != nil {
+		if !o.fail(err) {
+			return err
+		}
+	} else {
+		o.copied++
+	}
+
+	if err := a.close(); err != nil {
+		return &Error{Op: "Close", Src: src, Err: err}
+	}
+
+	return o.multiError()
+}
+
+// archiveWalker walks a tree for an archiver.
+type archiveWalker struct {
+	a archiver
+	o *options
+	// links maps files with multiple hard links to the first name they
+	// were written under
+	links map[fileID]string
+}
+
+// add writes the entry at src, and everything below it, under name.
+func (w *archiveWalker) add(src, name string) error {
+	obj, err := newObjectFS(w.o.fsys, src)
+	if err != nil {
+		return err
+	}
+
+	// a src that is not a directory is stored under its base name
+	if _, ok := obj.(directory); !ok && name == "." {
+		name = path.Base(filepath.ToSlash(src))
+	}
+
+	if policy, ok := specialPolicy(obj, w.o); ok {
+		switch policy {
+		case SpecialSkip:
+			return nil
+		case SpecialCreate:
+		default:
+			return &Error{Op: "writeArchive", Src: src, Err: unsupportedType(obj.Info())}
+		}
+	}
+
+	e := &archiveEntry{obj: obj, name: name, o: w.o}
+
+	switch obj.(type) {
+	case link:
+		if e.target, err = readlink(w.o.fsys, src); err != nil {
+			return &Error{Op: "Readlink", Src: src, Err: err}
+		}
+	case file:
+		if id, nlink, ok := inode(obj.Info()); ok && nlink > 1 {
+			if first, seen := w.links[id]; seen {
+				e.linkname = first
+			} else {
+				w.links[id] = name
+			}
+		}
+	}
+
+	if err = w.a.writeEntry(e); err != nil {
+		return err
+	}
+
+	if _, ok := obj.(directory); ok {
+		return w.addChildren(src, name)
+	}
+
+	return nil
+}
+
+// addChildren writes each child of the directory at src below name.
+func (w *archiveWalker) addChildren(src, name string) error {
+	children, err := readDir(w.o.fsys, src)
+	if err != nil {
+		return &Error{Op: "ReadDir", Src: src, Dst: name, Err: err}
+	}
+
+	for _, child := range children {
+		childSrc := join(w.o.fsys, src, child.Name())
+
+		if !w.o.included(childSrc, child) {
+			continue
+		}
+
+		if err = w.add(childSrc, path.Join(name, child.Name())); err != nil {
+			if w.o.fail(err) {
+				continue
+			}
+
+			return err
+		}
+
+		w.o.copied++
+	}
+
+	return nil
+}
@@ -4,23 +4,27 @@ package copy
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-
-	"github.com/pkg/errors"
 )
 
 // interface for copying files, directories, or links
 type copyObject interface {
-	copyTo(dst string, linkOrCopy bool) error
-	Path() string
-	Info() os.FileInfo
+	Object
+	copyTo(dst string, o *options) error
 }
 
 // create a new object based on what type of file it is
 func newObject(path string) (copyObject, error) {
-	fi, err := os.Lstat(path)
+	return newObjectFS(nil, path)
+}
+
+// create a new object for the file at path in fsys, or on disk if fsys is nil
+func newObjectFS(fsys fs.FS, path string) (copyObject, error) {
+	fi, err := lstat(fsys, path)
 	if err != nil {
-		return nil, errors.Wrapf(err, "Lstat(%s)", path)
+		return nil, &Error{Op: "Lstat", Src: path, Err: err}
 	}
 
 	switch {
@@ -30,44 +34,110 @@ func newObject(path string) (copyObject, error) {
 		return newDirectory(path, fi), nil
 	case fi.Mode().IsRegular():
 		return newFile(path, fi), nil
+	case fi.Mode()&os.ModeNamedPipe != 0:
+		return newFIFO(path, fi), nil
+	case fi.Mode()&os.ModeDevice != 0:
+		return newDevice(path, fi), nil
+	case fi.Mode()&os.ModeSocket != 0:
+		return newSocket(path, fi), nil
 	default:
-		return nil, fmt.Errorf("unsupported file type %s", fi.Mode().String())
+		return nil, &Error{Op: "newObject", Src: path, Err: unsupportedType(fi)}
 	}
 }
 
 // All copies the src file to the dst path.
-func All(src, dst string) error {
-	obj, err := newObject(src)
-	if err != nil {
-		return errors.Wrapf(err, "newObject(%s)", src)
-	}
-
-	if err = obj.copyTo(dst, false); err != nil {
-		return errors.Wrapf(err, "copyTo(%s,%t)", dst, false)
-	}
-
-	return nil
+func All(src, dst string, opts ...Option) error {
+	return copyAll(src, dst, newOptions(false, opts))
 }
 
 // LinkOrCopy first attempts to hardlink src to dst and falls back
 // to a regular recursive copy if that fails. This is useful when
 // you might be copying over partition boundaries where a link will
 // fail.
-func LinkOrCopy(src, dst string) error {
-	obj, err := newObject(src)
+func LinkOrCopy(src, dst string, opts ...Option) error {
+	return copyAll(src, dst, newOptions(true, opts))
+}
+
+// copyAll copies src to dst using the configuration in o.
+func copyAll(src, dst string, o *options) error {
+	defer o.closeEvents()
+
+	if o.resume {
+		if err := o.startJournal(dst); err != nil {
+			o.emitError(err)
+			return err
+		}
+	}
+
+	return o.finishJournal(copyRoot(src, dst, o))
+}
+
+// copyRoot copies the top-level src to dst.
+func copyRoot(src, dst string, o *options) error {
+	stop := o.timer(phaseScan)
+	obj, err := newObjectFS(o.fsys, src)
+	stop()
+
 	if err != nil {
-		return errors.Wrapf(err, "newObject(%s)", src)
+		o.emitError(err)
+		return err
 	}
 
-	if err = obj.copyTo(dst, true); err != nil {
-		return errors.Wrapf(err, "copyTo(%s,%t)", dst, true)
+	if err = o.checkNested(src, dst, obj.Info()); err != nil {
+		o.emitError(err)
+		return err
 	}
 
-	return nil
+	o.startMapping(src, dst, obj.Info())
+
+	errs := len(o.errs)
+
+	switch err = o.copyTo(obj, dst); {
+	case err == ErrSkip:
+	case err != nil:
+		if !o.failed(err) {
+			o.emitError(err)
+			return err
+		}
+	default:
+		o.countCopied(errs)
+	}
+
+	if err = o.syncDone(dst); err != nil {
+		o.emitError(err)
+		return err
+	}
+
+	return o.multiError()
+}
+
+// copyTo copies obj to dst, running the configured hooks around the copy.
+// It returns ErrSkip if a hook skipped obj.
+func (o *options) copyTo(obj copyObject, dst string) error {
+	if err := o.before(obj, dst); err != nil {
+		if err == ErrSkip {
+			o.emit(Event{Type: EventSkipped, Src: obj.Path(), Dst: dst, Info: obj.Info()})
+		}
+
+		return err
+	}
+
+	return o.after(obj, dst, o.handle(obj, dst))
+}
+
+// unsupportedType returns an ErrUnsupportedType error describing the type of fi.
+func unsupportedType(fi os.FileInfo) error {
+	return fmt.Errorf("%w %s", ErrUnsupportedType, fi.Mode().String())
+}
+
+// preserveTimes sets the access and modification times of path in d to the
+// modification time in fi.
+func preserveTimes(d DestFS, path string, fi os.FileInfo) error {
+	return d.Chtimes(path, fi.ModTime(), fi.ModTime())
 }
 
 // internal function to throw away file close errors in deferred
 // functions
-func closeFile(f *os.File) {
+func closeFile(f io.Closer) {
 	_ = f.Close()
 }
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func mustCreateTestDirectory(t *testing.T, parent, name string) string {
@@ -79,10 +80,6 @@ func TestNewObjectError(t *testing.T) {
 	if _, err := newObject("nofile"); err == nil {
 		t.Error("expected error when file did not exist but no error was returned")
 	}
-
-	if _, err := newObject("/dev/tty0"); err == nil {
-		t.Error("expected error when file was non-regular, directory, or symlink")
-	}
 }
 
 func mustBeSameFile(t *testing.T, f1, f2 string) {
@@ -172,3 +169,32 @@ func TestLinkOrCopyDirectory(t *testing.T) {
 	mustBeSameFile(t, d2, dst)
 	mustBeSameFile(t, f.Name(), filepath.Join(dst, "file1"))
 }
+
+func TestPreserveTimes(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "preservetimes")
+	d2 := mustCreateTestDirectory(t, d, "src")
+	f := mustCreateTestFile(t, filepath.Join(d2, "file1"))
+	mtime := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+
+	for _, path := range []string{f.Name(), d2} {
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(d, "dst")
+	if err := All(d2, dst, PreserveTimes()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{dst, filepath.Join(dst, "file1")} {
+		fi, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !fi.ModTime().Equal(mtime) {
+			t.Errorf("expected %s mtime %v but got %v", path, mtime, fi.ModTime())
+		}
+	}
+}
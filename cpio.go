@@ -0,0 +1,219 @@
+package copy
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	cpioMagic   = "070701"
+	cpioTrailer = "TRAILER!!!"
+)
+
+// Unix file type and mode bits as stored in a cpio header.
+const (
+	unixIFIFO  = 0010000
+	unixIFCHR  = 0020000
+	unixIFDIR  = 0040000
+	unixIFBLK  = 0060000
+	unixIFREG  = 0100000
+	unixIFLNK  = 0120000
+	unixIFSOCK = 0140000
+	unixISUID  = 0004000
+	unixISGID  = 0002000
+	unixISVTX  = 0001000
+)
+
+// ToCpio writes src and everything below it to w as an SVR4 "newc" cpio
+// archive, the format used for Linux initramfs images. Entry names are
+// relative to src, which itself is stored as "." when it is a directory.
+// Files with several hard links share an inode number and their content is
+// stored with the first name only. Inode numbers are assigned in walk order
+// rather than taken from the source so the same tree always produces the
+// same archive.
+//
+// ToCpio honors the Filter, ContinueOnError and special file policy options,
+// so device nodes are stored when DevicePolicy is SpecialCreate, and the
+// RootOwner, ModTime, ModeMask and Reproducible options.
+func ToCpio(src string, w io.Writer, opts ...Option) error {
+	c := &cpioWriter{w: w, inodes: make(map[string]uint32)}
+
+	return writeArchive(src, c, newOptions(false, opts))
+}
+
+// cpioWriter is an archiver that writes a newc cpio archive.
+type cpioWriter struct {
+	w io.Writer
+	// n is the number of bytes written, used for padding
+	n int64
+	// inodes maps the first name of a hard linked file to its inode number
+	inodes map[string]uint32
+	ino    uint32
+}
+
+// cpioHeader holds the fields of a newc header.
+type cpioHeader struct {
+	ino, mode, uid, gid, nlink, mtime, size uint32
+	rdevMajor, rdevMinor                    uint32
+	name                                    string
+}
+
+func (c *cpioWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+func (c *cpioWriter) writeEntry(e *archiveEntry) error {
+	info := e.obj.Info()
+	hdr := cpioHeader{
+		mode:  unixMode(e.o.mode(info.Mode())),
+		nlink: 1,
+		mtime: cpioTime(info, e.o),
+		name:  e.name,
+	}
+
+	c.ino++
+	hdr.ino = c.ino
+
+	if _, nlink, ok := inode(info); ok {
+		hdr.nlink = uint32(nlink)
+	}
+
+	// the link count of a directory depends on the file system
+	if _, ok := e.obj.(directory); ok && e.o.reproducible {
+		hdr.nlink = 2
+	}
+
+	if uid, gid, ok := owner(info); ok && !e.o.rootOwner {
+		hdr.uid, hdr.gid = uint32(uid), uint32(gid)
+	}
+
+	switch e.obj.(type) {
+	case file:
+		if e.linkname != "" {
+			hdr.ino = c.inodes[e.linkname]
+		} else {
+			hdr.size = uint32(info.Size())
+			if hdr.nlink > 1 {
+				c.inodes[e.name] = hdr.ino
+			}
+		}
+	case link:
+		hdr.size = uint32(len(e.target))
+	case device:
+		var ok bool
+		if hdr.rdevMajor, hdr.rdevMinor, ok = devNumbers(info); !ok {
+			return &Error{Op: "ToCpio", Src: e.obj.Path(), Dst: e.name, Err: fmt.Errorf("device numbers: %w", errNotSupported)}
+		}
+	}
+
+	if err := c.writeHeader(&hdr); err != nil {
+		return &Error{Op: "Write", Src: e.obj.Path(), Dst: e.name, Err: err}
+	}
+
+	start := c.n
+
+	switch e.obj.(type) {
+	case file:
+		// only the first name of a hard linked file carries the content
+		if e.linkname != "" {
+			return nil
+		}
+
+		if err := e.writeContent(c); err != nil {
+			return err
+		}
+	case link:
+		if _, err := io.WriteString(c, e.target); err != nil {
+			return &Error{Op: "Write", Src: e.obj.Path(), Dst: e.name, Err: err}
+		}
+	default:
+		return nil
+	}
+
+	// the header already records the size, so a file that changed while
+	// it was read would corrupt the archive
+	if c.n-start != int64(hdr.size) {
+		return &Error{Op: "Copy", Src: e.obj.Path(), Dst: e.name,
+			Err: fmt.Errorf("wrote %d bytes, expected %d", c.n-start, hdr.size)}
+	}
+
+	return wrapError("Write", e.obj.Path(), e.name, c.pad())
+}
+
+func (c *cpioWriter) close() error {
+	c.ino++
+
+	return c.writeHeader(&cpioHeader{ino: c.ino, nlink: 1, name: cpioTrailer})
+}
+
+// writeHeader writes hdr followed by the padded name.
+func (c *cpioWriter) writeHeader(hdr *cpioHeader) error {
+	_, err := fmt.Fprintf(c, "%s%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%s\x00",
+		cpioMagic, hdr.ino, hdr.mode, hdr.uid, hdr.gid, hdr.nlink, hdr.mtime, hdr.size,
+		0, 0, hdr.rdevMajor, hdr.rdevMinor, len(hdr.name)+1, 0, hdr.name)
+	if err != nil {
+		return err
+	}
+
+	return c.pad()
+}
+
+// pad writes zeros up to the next four byte boundary.
+func (c *cpioWriter) pad() error {
+	if rem := c.n % 4; rem != 0 {
+		_, err := c.Write(make([]byte, 4-rem))
+		return err
+	}
+
+	return nil
+}
+
+// cpioTime returns the modification time recorded for info.
+func cpioTime(info os.FileInfo, o *options) uint32 {
+	mtime := o.modTimeOf(info)
+	if mtime.Unix() < 0 {
+		return 0
+	}
+
+	return uint32(mtime.Unix())
+}
+
+// unixMode converts m to the Unix mode bits stored in archive headers.
+func unixMode(m os.FileMode) uint32 {
+	mode := uint32(m.Perm())
+
+	switch {
+	case m.IsDir():
+		mode |= unixIFDIR
+	case m&os.ModeSymlink != 0:
+		mode |= unixIFLNK
+	case m&os.ModeNamedPipe != 0:
+		mode |= unixIFIFO
+	case m&os.ModeSocket != 0:
+		mode |= unixIFSOCK
+	case m&os.ModeCharDevice != 0:
+		mode |= unixIFCHR
+	case m&os.ModeDevice != 0:
+		mode |= unixIFBLK
+	default:
+		mode |= unixIFREG
+	}
+
+	if m&os.ModeSetuid != 0 {
+		mode |= unixISUID
+	}
+
+	if m&os.ModeSetgid != 0 {
+		mode |= unixISGID
+	}
+
+	if m&os.ModeSticky != 0 {
+		mode |= unixISVTX
+	}
+
+	return mode
+}
@@ -0,0 +1,177 @@
+package copy
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type cpioTestEntry struct {
+	cpioHeader
+	data string
+}
+
+func mustReadCpio(t *testing.T, b []byte) []cpioTestEntry {
+	t.Helper()
+
+	var entries []cpioTestEntry
+
+	for off := 0; ; {
+		if len(b) < off+110 || string(b[off:off+6]) != cpioMagic {
+			t.Fatalf("expected cpio header at offset %d", off)
+		}
+
+		var fields [13]uint32
+		for i := range fields {
+			start := off + 6 + i*8
+			v, err := strconv.ParseUint(string(b[start:start+8]), 16, 32)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields[i] = uint32(v)
+		}
+
+		namesize := int(fields[11])
+		name := string(b[off+110 : off+110+namesize-1])
+		off = (off + 110 + namesize + 3) &^ 3
+
+		if name == cpioTrailer {
+			return entries
+		}
+
+		size := int(fields[6])
+		entries = append(entries, cpioTestEntry{
+			cpioHeader: cpioHeader{
+				ino: fields[0], mode: fields[1], uid: fields[2], gid: fields[3],
+				nlink: fields[4], mtime: fields[5], size: fields[6],
+				rdevMajor: fields[9], rdevMinor: fields[10], name: name,
+			},
+			data: string(b[off : off+size]),
+		})
+		off = (off + size + 3) &^ 3
+	}
+}
+
+func TestToCpio(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "tocpio")
+	src := mustCreateTestTree(t, d)
+
+	if err := os.Link(filepath.Join(src, "sub", "file1"), filepath.Join(src, "hardlink")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := ToCpio(src, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := mustReadCpio(t, buf.Bytes())
+
+	var names []string
+	byName := make(map[string]cpioTestEntry)
+	for _, e := range entries {
+		names = append(names, e.name)
+		byName[e.name] = e
+	}
+
+	expected := []string{".", "file2", "hardlink", "link1", "sub", "sub/file1"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected entries %v but got %v", expected, names)
+	}
+
+	if mode := byName["."].mode; mode&0170000 != unixIFDIR {
+		t.Errorf("expected root to be a directory but got mode %o", mode)
+	}
+
+	link1 := byName["link1"]
+	if link1.mode&0170000 != unixIFLNK || link1.data != "sub/file1" {
+		t.Errorf("expected symlink to sub/file1 but got mode %o data '%s'", link1.mode, link1.data)
+	}
+
+	first, second := byName["hardlink"], byName["sub/file1"]
+	if first.ino != second.ino || first.nlink != 2 {
+		t.Errorf("expected hard links to share an inode but got %d/%d with %d links", first.ino, second.ino, first.nlink)
+	}
+	if first.data != "test" || second.size != 0 {
+		t.Errorf("expected content only with the first name but got '%s' and size %d", first.data, second.size)
+	}
+	if byName["file2"].ino == first.ino {
+		t.Error("expected distinct files to have distinct inodes")
+	}
+}
+
+func TestToCpioNormalize(t *testing.T) {
+	src := mustCreateTestTree(t, mustCreateTestDirectory(t, "", "tocpio"))
+	mtime := time.Unix(1500000000, 0)
+
+	var buf bytes.Buffer
+	if err := ToCpio(src, &buf, RootOwner(), ModTime(mtime)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range mustReadCpio(t, buf.Bytes()) {
+		if e.uid != 0 || e.gid != 0 {
+			t.Errorf("expected %s to be owned by root but got %d:%d", e.name, e.uid, e.gid)
+		}
+		if int64(e.mtime) != mtime.Unix() {
+			t.Errorf("expected %s to have mtime %d but got %d", e.name, mtime.Unix(), e.mtime)
+		}
+	}
+}
+
+func TestToCpioDevice(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("device numbers are only checked on Linux")
+	}
+
+	var buf bytes.Buffer
+	if err := ToCpio(os.DevNull, &buf); err == nil {
+		t.Fatal("expected error storing a device without DevicePolicy(SpecialCreate)")
+	}
+
+	buf.Reset()
+	if err := ToCpio(os.DevNull, &buf, DevicePolicy(SpecialCreate)); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := mustReadCpio(t, buf.Bytes())
+	if len(entries) != 1 {
+		t.Fatalf("expected a single entry but got %d", len(entries))
+	}
+
+	e := entries[0]
+	if e.name != "null" || e.mode&0170000 != unixIFCHR || e.rdevMajor != 1 || e.rdevMinor != 3 {
+		t.Errorf("expected character device null 1:3 but got %s mode %o %d:%d", e.name, e.mode, e.rdevMajor, e.rdevMinor)
+	}
+}
+
+// unknownDevice describes a device node without platform device numbers.
+type unknownDevice struct {
+	os.FileInfo
+}
+
+func (unknownDevice) Mode() os.FileMode { return os.ModeDevice | 0600 }
+func (unknownDevice) Sys() interface{}  { return nil }
+
+func TestCpioUnknownDeviceNumbers(t *testing.T) {
+	f := mustCreateTestFile(t, filepath.Join(mustCreateTestDirectory(t, "", "cpio"), "dev"))
+
+	fi, err := os.Lstat(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	c := &cpioWriter{w: &buf, inodes: make(map[string]uint32)}
+	e := &archiveEntry{obj: newDevice(f.Name(), unknownDevice{fi}), name: "dev", o: newOptions(false, nil)}
+
+	if err := c.writeEntry(e); !errors.Is(err, errNotSupported) {
+		t.Errorf("expected device without numbers to be rejected but got %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+package copy
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// DestFS is a writable file system that copies are written to. Paths are
+// the destination paths passed to the copy functions, joined with the
+// names of copied entries.
+type DestFS interface {
+	// Mkdir creates the named directory. Its parent must exist.
+	Mkdir(name string, perm os.FileMode) error
+	// Create creates or truncates the named regular file for writing.
+	Create(name string) (io.WriteCloser, error)
+	// Symlink creates newname as a symbolic link to oldname.
+	Symlink(oldname, newname string) error
+	// Link creates newname as a hard link to the source file oldname. It
+	// may fail if the destination cannot link to the source, in which case
+	// LinkOrCopy falls back to a copy.
+	Link(oldname, newname string) error
+	// Chmod changes the mode of the named file.
+	Chmod(name string, mode os.FileMode) error
+	// Chtimes changes the access and modification times of the named file.
+	Chtimes(name string, atime, mtime time.Time) error
+	// Remove removes the named file or empty directory.
+	Remove(name string) error
+	// Lstat describes the named file without following symbolic links.
+	Lstat(name string) (os.FileInfo, error)
+	// Readlink returns the destination of the named symbolic link.
+	Readlink(name string) (string, error)
+	// ReadDir returns the entries of the named directory sorted by name.
+	ReadDir(name string) ([]os.FileInfo, error)
+}
+
+// Destination sets the file system copies are written to. The default is
+// the local disk.
+func Destination(d DestFS) Option {
+	return func(o *options) {
+		o.dest = d
+	}
+}
+
+// OSFS is a DestFS that writes to the local disk with the os package.
+type OSFS struct{}
+
+// Mkdir calls os.Mkdir.
+func (OSFS) Mkdir(name string, perm os.FileMode) error {
+	return os.Mkdir(name, perm)
+}
+
+// MkdirAll calls os.MkdirAll.
+func (OSFS) MkdirAll(name string, perm os.FileMode) error {
+	return os.MkdirAll(name, perm)
+}
+
+// Create calls os.Create.
+func (OSFS) Create(name string) (io.WriteCloser, error) {
+	return os.Create(name)
+}
+
+// Symlink calls os.Symlink.
+func (OSFS) Symlink(oldname, newname string) error {
+	return os.Symlink(oldname, newname)
+}
+
+// Link calls os.Link.
+func (OSFS) Link(oldname, newname string) error {
+	return os.Link(oldname, newname)
+}
+
+// Chmod calls os.Chmod.
+func (OSFS) Chmod(name string, mode os.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+// Chtimes calls os.Chtimes.
+func (OSFS) Chtimes(name string, atime, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+// Lchtimes changes the access and modification times of the named file
+// without following a symbolic link. It does nothing on platforms other
+// than Linux.
+func (OSFS) Lchtimes(name string, atime, mtime time.Time) error {
+	return lchtimesOS(name, atime, mtime)
+}
+
+// Fsync flushes the named file or directory to stable storage. Directories
+// cannot be flushed on Windows, where it does nothing for them.
+func (OSFS) Fsync(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+
+	defer closeFile(f)
+
+	if runtime.GOOS == "windows" {
+		if fi, err := f.Stat(); err == nil && fi.IsDir() {
+			return nil
+		}
+	}
+
+	return f.Sync()
+}
+
+// Syncfs flushes the whole file system containing name to stable storage.
+// It is only supported on Linux.
+func (OSFS) Syncfs(name string) error {
+	return syncfs(name)
+}
+
+// Remove calls os.Remove.
+func (OSFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// RemoveAll calls os.RemoveAll.
+func (OSFS) RemoveAll(name string) error {
+	return os.RemoveAll(name)
+}
+
+// Lstat calls os.Lstat.
+func (OSFS) Lstat(name string) (os.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+// Readlink calls os.Readlink.
+func (OSFS) Readlink(name string) (string, error) {
+	return os.Readlink(name)
+}
+
+// ReadDir reads the directory with the same ordering as os.ReadDir.
+func (OSFS) ReadDir(name string) ([]os.FileInfo, error) {
+	return readDir(nil, name)
+}
+
+// Mknod creates a special file with the type, permissions and device
+// number of fi.
+func (OSFS) Mknod(name string, fi os.FileInfo) error {
+	return mknod(name, fi)
+}
+
+// onDisk reports whether d writes to the local disk.
+func onDisk(d DestFS) bool {
+	switch d.(type) {
+	case OSFS, *OSFS:
+		return true
+	default:
+		return false
+	}
+}
+
+// mkdirAll creates name and any missing parents in d. It uses the MkdirAll
+// method of d when it has one.
+func mkdirAll(d DestFS, name string, perm os.FileMode) error {
+	if m, ok := d.(interface {
+		MkdirAll(string, os.FileMode) error
+	}); ok {
+		return m.MkdirAll(name, perm)
+	}
+
+	if fi, err := d.Lstat(name); err == nil {
+		if fi.IsDir() {
+			return nil
+		}
+
+		return &os.PathError{Op: "mkdir", Path: name, Err: syscall.ENOTDIR}
+	}
+
+	if parent := filepath.Dir(name); parent != name {
+		if err := mkdirAll(d, parent, perm); err != nil {
+			return err
+		}
+	}
+
+	return d.Mkdir(name, perm)
+}
+
+// removeAll removes name and everything below it from d. It uses the
+// RemoveAll method of d when it has one.
+func removeAll(d DestFS, name string) error {
+	if r, ok := d.(interface{ RemoveAll(string) error }); ok {
+		return r.RemoveAll(name)
+	}
+
+	fi, err := d.Lstat(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if fi.IsDir() {
+		children, err := d.ReadDir(name)
+		if err != nil {
+			return err
+		}
+
+		for _, child := range children {
+			if err = removeAll(d, filepath.Join(name, child.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return d.Remove(name)
+}
+
+// lchtimes changes the times of name in d without following a symbolic
+// link. d must have a Lchtimes method.
+func lchtimes(d DestFS, name string, atime, mtime time.Time) error {
+	l, ok := d.(interface {
+		Lchtimes(string, time.Time, time.Time) error
+	})
+	if !ok {
+		return &os.PathError{Op: "lchtimes", Path: name, Err: errNotSupported}
+	}
+
+	return l.Lchtimes(name, atime, mtime)
+}
+
+// mknodDest creates the special file described by fi at name in d, which
+// must have a Mknod method.
+func mknodDest(d DestFS, name string, fi os.FileInfo) error {
+	m, ok := d.(interface {
+		Mknod(string, os.FileInfo) error
+	})
+	if !ok {
+		return &os.PathError{Op: "mknod", Path: name, Err: unsupportedType(fi)}
+	}
+
+	return m.Mknod(name, fi)
+}
@@ -0,0 +1,60 @@
+package copy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDestination(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "destination")
+	src := mustCreateTestDirectory(t, d, "src")
+	sub := mustMkdirAll(t, filepath.Join(src, "sub"))
+	mustCreateTestFile(t, filepath.Join(sub, "file1"))
+	mustCreateTestLink(t, filepath.Join(src, "link1"), "sub/file1")
+
+	m := NewMemFS()
+	if err := mkdirAll(m, "/out/stale", os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LinkOrCopy(src, "/out", Destination(m), Mirror()); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := m.ReadFile("/out/sub/file1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "test" {
+		t.Errorf("expected 'test' but got '%s'", b)
+	}
+
+	target, err := m.Readlink("/out/link1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if target != "sub/file1" {
+		t.Errorf("expected link to 'sub/file1' but got '%s'", target)
+	}
+
+	if _, err = m.Lstat("/out/stale"); !os.IsNotExist(err) {
+		t.Errorf("expected /out/stale to be removed but got %v", err)
+	}
+
+	si, err := os.Lstat(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	di, err := m.Lstat("/out/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if si.Mode() != di.Mode() {
+		t.Errorf("expected mode %v but got %v", si.Mode(), di.Mode())
+	}
+}
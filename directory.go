@@ -1,11 +1,8 @@
 package copy
 
 import (
-	"io/ioutil"
 	"os"
 	"path/filepath"
-
-	"github.com/pkg/errors"
 )
 
 type directory struct {
@@ -17,51 +14,167 @@ func newDirectory(path string, fi os.FileInfo) directory {
 }
 
 // copyTo recursively copies directories from d.path to dst
-func (d directory) copyTo(dst string, linkOrCopy bool) error {
+func (d directory) copyTo(dst string, o *options) error {
+	// get all children before creating anything, which could be nested in
+	// the source
+	stop := o.timer(phaseScan)
+	children, err := readDir(o.fsys, d.path)
+	stop()
+
+	if err != nil {
+		return &Error{Op: "ReadDir", Src: d.path, Dst: dst, Err: err}
+	}
+
+	_, err = o.dest.Lstat(dst)
+	existed := err == nil
+
 	// create new directory with source mode
-	if err := os.MkdirAll(dst, d.info.Mode()); err != nil {
-		return errors.Wrapf(err, "MkdirAll(%s,%s)", dst, d.info.Mode().String())
+	if err := o.mkdirAll(dst, o.mode(d.info.Mode())); err != nil {
+		return &Error{Op: "MkdirAll", Src: d.path, Dst: dst, Err: err}
 	}
 
-	// get all children
-	children, err := ioutil.ReadDir(d.path)
-	if err != nil {
-		return errors.Wrapf(err, "ReadDir(%s)", d.path)
+	// the umask filters the mode of a new directory. An existing one keeps
+	// its own unless the copy must be reproducible.
+	if !existed || o.reproducible {
+		stop = o.timer(phaseMetadata)
+		err = o.dest.Chmod(dst, o.mode(d.info.Mode()))
+		stop()
+
+		if err != nil {
+			return &Error{Op: "Chmod", Src: d.path, Dst: dst, Err: err}
+		}
 	}
 
+	o.emit(Event{Type: EventDirCreated, Src: d.path, Dst: dst, Info: d.info})
+
+	// names of the copies of the children, which mirror keeps
+	kept := make(map[string]bool, len(children))
+
 	// Make sure we *can* copy the children if any
 	if len(children) > 0 && d.info.Mode()&0200 == 0 {
-		if err := os.Chmod(dst, d.info.Mode()|0200); err != nil {
-			return errors.Wrapf(err, "Chmod(%s,%s)", dst, d.info.Mode()|0200)
+		if err := o.dest.Chmod(dst, o.mode(d.info.Mode())|0200); err != nil {
+			return &Error{Op: "Chmod", Src: d.path, Dst: dst, Err: err}
 		}
+
+		o.debug("widened directory mode to copy children", d.path, dst, "mode", d.info.Mode())
 	}
 
 	// copy each child recursively
 	for _, child := range children {
-		childSrc := filepath.Join(d.path, child.Name())
-		childDst := filepath.Join(dst, child.Name())
+		childSrc := join(o.fsys, d.path, child.Name())
 
-		obj, err := newObject(childSrc)
+		// the destination of a nested copy is not part of the source
+		if o.isNestedDst(child) {
+			o.debug("skipped destination nested in the source", childSrc, dst)
+			o.emit(Event{Type: EventSkipped, Src: childSrc, Info: child})
+			continue
+		}
+
+		if !o.included(childSrc, child) {
+			skipped := filepath.Join(dst, child.Name())
+			o.debug("skipped by filter", childSrc, skipped)
+			o.emit(Event{Type: EventSkipped, Src: childSrc, Dst: skipped, Info: child})
+			continue
+		}
+
+		childDst, ok, err := o.destination(dst, childSrc, child)
 		if err != nil {
-			return errors.Wrapf(err, "newObject(%s)", childSrc)
+			if o.failed(err) {
+				continue
+			}
+
+			return err
+		}
+
+		if !ok {
+			o.debug("skipped by path mapping", childSrc, "")
+			o.emit(Event{Type: EventSkipped, Src: childSrc, Info: child})
+			continue
 		}
 
-		if err = obj.copyTo(childDst, linkOrCopy); err != nil {
-			return errors.Wrapf(err, "copyTo(%s,%t)", childDst, linkOrCopy)
+		// mapped destinations are kept through o.mapped
+		if o.mapPath == nil {
+			kept[filepath.Base(childDst)] = true
 		}
+
+		stop = o.timer(phaseScan)
+		obj, err := newObjectFS(o.fsys, childSrc)
+		stop()
+
+		if err != nil {
+			if o.failed(err) {
+				continue
+			}
+
+			return err
+		}
+
+		// a skipped entry is neither copied nor failed
+		errs := len(o.errs)
+		err = o.copyTo(obj, childDst)
+		if err == ErrSkip {
+			continue
+		}
+
+		if err != nil {
+			if o.failed(err) {
+				continue
+			}
+
+			return err
+		}
+
+		o.countCopied(errs)
 	}
 
-	// Restore the directories modes if we made it writeable
-	if len(children) > 0 && d.info.Mode()&0200 == 0 {
-		if err := os.Chmod(dst, d.info.Mode()); err != nil {
-			return errors.Wrapf(err, "Chmod(%s,%s)", dst, d.info.Mode())
+	// remove destination entries that are not in the source
+	if o.mirror {
+		stop = o.timer(phaseMirror)
+		err = d.mirror(dst, kept, o)
+		stop()
+
+		if err != nil {
+			return err
 		}
 	}
 
+	stop = o.timer(phaseMetadata)
+	err = d.restore(dst, len(children) > 0, o)
+	stop()
+
+	if err != nil {
+		return err
+	}
+
+	// flush the entries created in the directory
+	if err := o.syncDir(dst); err != nil {
+		return &Error{Op: "Fsync", Src: d.path, Dst: dst, Err: err}
+	}
+
 	// successful
 	return nil
 }
 
+// restore sets the mode, if it was widened to copy children, and times of
+// the copy at dst once its children are copied.
+func (d directory) restore(dst string, widened bool, o *options) error {
+	// Restore the directories modes if we made it writeable
+	if widened && d.info.Mode()&0200 == 0 {
+		if err := o.dest.Chmod(dst, o.mode(d.info.Mode())); err != nil {
+			return &Error{Op: "Chmod", Src: d.path, Dst: dst, Err: err}
+		}
+
+		o.debug("restored directory mode", d.path, dst, "mode", d.info.Mode())
+	}
+
+	// set times last since creating children updates them
+	return wrapError("Chtimes", d.path, dst, o.setTimes(dst, d.info))
+}
+
+func (d directory) Kind() Kind {
+	return KindDirectory
+}
+
 func (d directory) String() string {
 	return "directory: " + d.path
 }
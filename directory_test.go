@@ -2,6 +2,8 @@ package copy
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -52,7 +54,38 @@ func TestDirectoryCopyToError(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := do.copyTo("nowhere", false); err == nil {
+	if err := do.copyTo(filepath.Join(t.TempDir(), "nowhere"), newOptions(false, nil)); err == nil {
 		t.Error("expected error when file did not exist but no error was returned")
 	}
 }
+
+func TestDirectoryKeepsExistingMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directory modes are not supported on windows")
+	}
+
+	d := mustCreateTestDirectory(t, "", "directorymode")
+	src := mustCreateTestDirectory(t, d, "src")
+	mustCreateTestFile(t, filepath.Join(src, "file"))
+
+	if err := os.Chmod(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := mustMkdirAll(t, filepath.Join(d, "dst"))
+	if err := os.Chmod(dst, 0777|os.ModeSticky); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := All(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode() != os.ModeDir|os.ModeSticky|0777 {
+		t.Errorf("expected existing destination to keep its mode but got %v", fi.Mode())
+	}
+}
@@ -0,0 +1,62 @@
+package copy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedType is returned for a file type that cannot be copied
+	// under the configured policy.
+	ErrUnsupportedType = errors.New("unsupported file type")
+	// ErrSpecialFile is returned for a named pipe, device node or socket
+	// under the SpecialError policy. It matches ErrUnsupportedType too.
+	ErrSpecialFile = fmt.Errorf("special file: %w", ErrUnsupportedType)
+	// ErrSameFile is returned when the source and destination of a copy are
+	// the same file.
+	ErrSameFile = errors.New("source and destination are the same file")
+	// ErrUnsafePath is returned when an archive entry would be written
+	// outside of the destination.
+	ErrUnsafePath = errors.New("path escapes destination")
+)
+
+// Error records a failed operation along with the source and destination of
+// the entry being copied when it failed.
+type Error struct {
+	// Op is the operation that failed, such as "Create" or "Symlink".
+	Op string
+	// Src is the source path of the entry, if any.
+	Src string
+	// Dst is the destination path of the entry, if any.
+	Dst string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *Error) Error() string {
+	parts := []string{e.Op}
+
+	for _, path := range []string{e.Src, e.Dst} {
+		if path != "" {
+			parts = append(parts, path)
+		}
+	}
+
+	return strings.Join(parts, " ") + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// wrapError returns err as an *Error for op on the entry copied from src to
+// dst, or nil if err is nil.
+func wrapError(op, src, dst string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &Error{Op: op, Src: src, Dst: dst, Err: err}
+}
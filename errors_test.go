@@ -0,0 +1,52 @@
+package copy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestErrorString(t *testing.T) {
+	e := &Error{Op: "Create", Src: "foo", Dst: "bar", Err: errors.New("failed")}
+	if es := e.Error(); es != "Create foo bar: failed" {
+		t.Errorf("expected 'Create foo bar: failed' but got '%s'", es)
+	}
+
+	e = &Error{Op: "Lstat", Src: "foo", Err: errors.New("failed")}
+	if es := e.Error(); es != "Lstat foo: failed" {
+		t.Errorf("expected 'Lstat foo: failed' but got '%s'", es)
+	}
+}
+
+func TestErrorAs(t *testing.T) {
+	err := All("none", "none")
+
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *Error but got %v", err)
+	}
+
+	if e.Op != "Lstat" || e.Src != "none" {
+		t.Errorf("expected Lstat of 'none' but got %s of '%s'", e.Op, e.Src)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected error to match os.ErrNotExist but got %v", err)
+	}
+}
+
+func TestErrSameFile(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "samefile")
+	f := mustCreateTestFile(t, filepath.Join(d, "file"))
+
+	if err := All(f.Name(), f.Name()); !errors.Is(err, ErrSameFile) {
+		t.Errorf("expected ErrSameFile but got %v", err)
+	}
+
+	mustBeSameFile(t, f.Name(), f.Name())
+
+	if err := LinkOrCopy(f.Name(), f.Name()); err != nil {
+		t.Errorf("expected no error when linking to the same file but got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package copy
+
+import (
+	"errors"
+	"os"
+)
+
+// EventType identifies what an Event reports.
+type EventType int
+
+const (
+	// EventDirCreated reports that the directory Dst was created, or
+	// already existed, and its children are about to be copied.
+	EventDirCreated EventType = iota
+	// EventFileCopied reports that Bytes bytes were copied from Src to Dst.
+	EventFileCopied
+	// EventFileLinked reports that Dst was hard linked to Src.
+	EventFileLinked
+	// EventSymlinkCreated reports that the symlink Dst was created.
+	EventSymlinkCreated
+	// EventSpecialCreated reports that the special file Dst was created.
+	EventSpecialCreated
+	// EventSkipped reports that Src was left out by the Filter, a
+	// BeforeCopy hook or a special file policy.
+	EventSkipped
+	// EventRemoved reports that Dst was removed by Mirror.
+	EventRemoved
+	// EventError reports a failure in Err. Src and Dst are set when Err is
+	// an *Error.
+	EventError
+)
+
+var eventTypeNames = [...]string{
+	EventDirCreated:     "DirCreated",
+	EventFileCopied:     "FileCopied",
+	EventFileLinked:     "FileLinked",
+	EventSymlinkCreated: "SymlinkCreated",
+	EventSpecialCreated: "SpecialCreated",
+	EventSkipped:        "Skipped",
+	EventRemoved:        "Removed",
+	EventError:          "Error",
+}
+
+func (t EventType) String() string {
+	if t < 0 || int(t) >= len(eventTypeNames) {
+		return "unknown"
+	}
+
+	return eventTypeNames[t]
+}
+
+// Event reports a single action taken by a copy.
+type Event struct {
+	Type EventType
+	// Src is the source path, empty for EventRemoved.
+	Src string
+	// Dst is the destination path.
+	Dst string
+	// Info describes the source, or the removed entry for EventRemoved. It
+	// is nil for EventError.
+	Info os.FileInfo
+	// Bytes is the number of bytes copied for EventFileCopied.
+	Bytes int64
+	// Err is the failure for EventError.
+	Err error
+}
+
+// Events sends an Event on ch for every entry a copy creates, links,
+// skips or removes and for every failure. With ContinueOnError each
+// recorded failure is sent; otherwise only the failure that stopped the
+// copy is.
+//
+// Events are sent synchronously from the copy, so a copy runs no faster
+// than ch is received from; a buffered channel absorbs bursts. ch is
+// closed when All, LinkOrCopy or FromFS returns, so a receiver can range
+// over it, and must therefore only be passed to a single copy. Other
+// functions do not send events.
+func Events(ch chan<- Event) Option {
+	return func(o *options) {
+		o.events = ch
+	}
+}
+
+// emit records e in the result and sends it if events are enabled.
+func (o *options) emit(e Event) {
+	o.record(e)
+
+	if o.events != nil {
+		o.events <- e
+	}
+}
+
+// emitError sends an EventError for err.
+func (o *options) emitError(err error) {
+	e := Event{Type: EventError, Err: err}
+
+	var ce *Error
+	if errors.As(err, &ce) {
+		e.Src, e.Dst = ce.Src, ce.Dst
+	}
+
+	o.emit(e)
+}
+
+// closeEvents closes the events channel once the copy is finished.
+func (o *options) closeEvents() {
+	if o.events != nil {
+		close(o.events)
+	}
+}
+
+// failed is fail for copies, which also report each recorded failure as
+// an event.
+func (o *options) failed(err error) bool {
+	if !o.fail(err) {
+		return false
+	}
+
+	o.emitError(err)
+
+	return true
+}
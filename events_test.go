@@ -0,0 +1,116 @@
+package copy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// mustCollectEvents runs run with an Events option and returns every
+// event received before the channel was closed.
+func mustCollectEvents(t *testing.T, run func(Option) error) ([]Event, error) {
+	t.Helper()
+
+	ch := make(chan Event)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- run(Events(ch))
+	}()
+
+	var events []Event
+	for e := range ch {
+		events = append(events, e)
+	}
+
+	return events, <-done
+}
+
+func eventSummary(root string, events []Event) []string {
+	var summary []string
+
+	for _, e := range events {
+		rel, _ := filepath.Rel(root, e.Dst)
+		summary = append(summary, e.Type.String()+" "+filepath.ToSlash(rel))
+	}
+
+	return summary
+}
+
+func TestEvents(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "events")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+	mustCreateTestFile(t, filepath.Join(src, "excluded"))
+	mustMkdirAll(t, filepath.Join(dst, "extra"))
+
+	exclude := func(path string, _ os.FileInfo) bool {
+		return filepath.Base(path) != "excluded"
+	}
+
+	events, err := mustCollectEvents(t, func(opt Option) error {
+		return All(src, dst, Filter(exclude), Mirror(), opt)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"DirCreated .",
+		"Skipped excluded",
+		"FileCopied file2",
+		"SymlinkCreated link1",
+		"DirCreated sub",
+		"FileCopied sub/file1",
+		"Removed extra",
+	}
+	if summary := eventSummary(dst, events); !reflect.DeepEqual(summary, expected) {
+		t.Errorf("expected events %v but got %v", expected, summary)
+	}
+
+	if events[2].Bytes != 4 {
+		t.Errorf("expected 4 bytes copied but got %d", events[2].Bytes)
+	}
+}
+
+func TestEventsLinked(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "events")
+	src := mustCreateTestFile(t, filepath.Join(d, "src")).Name()
+
+	events, err := mustCollectEvents(t, func(opt Option) error {
+		return LinkOrCopy(src, filepath.Join(d, "dst"), opt)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 1 || events[0].Type != EventFileLinked {
+		t.Errorf("expected a single FileLinked event but got %v", events)
+	}
+}
+
+func TestEventsError(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "events")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+	mustBlockFile(t, filepath.Join(dst, "file2"))
+
+	events, err := mustCollectEvents(t, func(opt Option) error {
+		return All(src, dst, ContinueOnError(), opt)
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var errs []Event
+	for _, e := range events {
+		if e.Type == EventError {
+			errs = append(errs, e)
+		}
+	}
+
+	if len(errs) != 1 || errs[0].Dst != filepath.Join(dst, "file2") {
+		t.Errorf("expected a single error for file2 but got %v", errs)
+	}
+}
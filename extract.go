@@ -0,0 +1,255 @@
+package copy
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// extractor writes archive entries below dst with the same rules as All.
+// Entry names are sanitized so nothing is written outside of dst.
+type extractor struct {
+	o   *options
+	dst string
+	// excluded holds directories rejected by the filter
+	excluded []string
+	// dirs holds directories whose metadata is set after all entries are
+	// written, since writing their children changes it
+	dirs []extractedDir
+}
+
+type extractedDir struct {
+	path string
+	info os.FileInfo
+}
+
+// target sanitizes the entry name and returns it cleaned along with the
+// destination path it is written to. Every parent of the destination path
+// below x.dst must be a real directory so that the write cannot be
+// redirected by a symlink.
+func (x *extractor) target(name string) (string, string, error) {
+	rel := path.Clean(strings.TrimPrefix(name, "./"))
+
+	if path.IsAbs(rel) || filepath.IsAbs(rel) || filepath.VolumeName(rel) != "" ||
+		rel == ".." || strings.HasPrefix(rel, "../") || strings.Contains(rel, `\`) {
+		return "", "", &Error{Op: "extract", Src: name, Dst: x.dst, Err: ErrUnsafePath}
+	}
+
+	if rel == "." {
+		return rel, x.dst, nil
+	}
+
+	parent := x.dst
+
+	for _, elem := range strings.Split(path.Dir(rel), "/") {
+		if elem == "." {
+			break
+		}
+
+		parent = filepath.Join(parent, elem)
+
+		fi, err := x.o.dest.Lstat(parent)
+		if os.IsNotExist(err) {
+			break
+		}
+
+		if err != nil {
+			return "", "", &Error{Op: "Lstat", Src: name, Dst: parent, Err: err}
+		}
+
+		if !fi.IsDir() {
+			return "", "", &Error{Op: "extract", Src: name, Dst: parent, Err: ErrUnsafePath}
+		}
+	}
+
+	return rel, filepath.Join(x.dst, filepath.FromSlash(rel)), nil
+}
+
+// skip reports whether the entry rel is excluded by the filter, either
+// itself or through an excluded parent directory.
+func (x *extractor) skip(rel string, info os.FileInfo) bool {
+	for _, dir := range x.excluded {
+		if strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+
+	if rel == "." || x.o.included(rel, info) {
+		return false
+	}
+
+	if info.IsDir() {
+		x.excluded = append(x.excluded, rel)
+	}
+
+	return true
+}
+
+// dir creates the directory entry name at dst. Like directory.copyTo it is
+// kept writable until every entry is written.
+func (x *extractor) dir(name, dst string, info os.FileInfo) error {
+	// do not follow an existing symlink below x.dst when creating the
+	// directory
+	if fi, err := x.o.dest.Lstat(dst); err == nil && fi.Mode()&os.ModeSymlink != 0 && dst != x.dst {
+		if err = x.o.dest.Remove(dst); err != nil {
+			return &Error{Op: "Remove", Src: name, Dst: dst, Err: err}
+		}
+	}
+
+	if err := mkdirAll(x.o.dest, dst, x.o.mode(info.Mode())); err != nil {
+		return &Error{Op: "MkdirAll", Src: name, Dst: dst, Err: err}
+	}
+
+	if err := x.o.dest.Chmod(dst, x.o.mode(info.Mode())|0200); err != nil {
+		return &Error{Op: "Chmod", Src: name, Dst: dst, Err: err}
+	}
+
+	x.dirs = append(x.dirs, extractedDir{path: dst, info: info})
+
+	return nil
+}
+
+// finish restores the mode, and times if requested, of each directory
+// entry, deepest first, even if extraction stopped with err. It returns
+// err, or else any errors recorded in continue-on-error mode.
+func (x *extractor) finish(err error) error {
+	for i := len(x.dirs) - 1; i >= 0; i-- {
+		d := x.dirs[i]
+
+		if cerr := x.o.dest.Chmod(d.path, x.o.mode(d.info.Mode())); cerr != nil && err == nil {
+			err = &Error{Op: "Chmod", Dst: d.path, Err: cerr}
+		}
+
+		if terr := x.o.setTimes(d.path, d.info); terr != nil && err == nil {
+			err = &Error{Op: "Chtimes", Dst: d.path, Err: terr}
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return x.o.multiError()
+}
+
+// replace makes room for the entry name at dst by creating its parents and
+// removing anything already there. Only a directory entry may be written to
+// x.dst itself.
+func (x *extractor) replace(name, dst string) error {
+	if dst == x.dst {
+		return &Error{Op: "extract", Src: name, Dst: dst, Err: ErrUnsafePath}
+	}
+
+	if err := mkdirAll(x.o.dest, filepath.Dir(dst), os.ModePerm); err != nil {
+		return &Error{Op: "MkdirAll", Src: name, Dst: dst, Err: err}
+	}
+
+	if err := x.o.dest.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return &Error{Op: "Remove", Src: name, Dst: dst, Err: err}
+	}
+
+	return nil
+}
+
+// file writes the regular file entry name at dst with the content of r.
+func (x *extractor) file(name, dst string, info os.FileInfo, r io.Reader) error {
+	if err := x.replace(name, dst); err != nil {
+		return err
+	}
+
+	df, err := x.o.dest.Create(dst)
+	if err != nil {
+		return &Error{Op: "Create", Src: name, Dst: dst, Err: err}
+	}
+
+	defer closeFile(df)
+
+	if err = x.o.dest.Chmod(dst, x.o.mode(info.Mode())); err != nil {
+		return &Error{Op: "Chmod", Src: name, Dst: dst, Err: err}
+	}
+
+	if _, err = io.Copy(df, r); err != nil {
+		return &Error{Op: "Copy", Src: name, Dst: dst, Err: err}
+	}
+
+	return wrapError("Chtimes", name, dst, x.o.setTimes(dst, info))
+}
+
+// symlink creates the symlink entry name at dst. The link target is not
+// checked since the link is never written through.
+func (x *extractor) symlink(name, dst, target string) error {
+	if err := x.replace(name, dst); err != nil {
+		return err
+	}
+
+	if err := x.o.dest.Symlink(target, dst); err != nil {
+		return &Error{Op: "Symlink", Src: name, Dst: dst, Err: err}
+	}
+
+	return wrapError("Lchtimes", name, dst, x.o.setLinkTimes(dst))
+}
+
+// hardlink links dst to the earlier entry linkname, which may not be a
+// symlink since some platforms link to the symlink target.
+func (x *extractor) hardlink(name, dst, linkname string) error {
+	_, target, err := x.target(linkname)
+	if err != nil {
+		return err
+	}
+
+	if fi, err := x.o.dest.Lstat(target); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		return &Error{Op: "extract", Src: name, Dst: target, Err: ErrUnsafePath}
+	}
+
+	if err = x.replace(name, dst); err != nil {
+		return err
+	}
+
+	return wrapError("Link", name, dst, x.o.dest.Link(target, dst))
+}
+
+// special creates the special file entry name at dst according to policy
+// and reports whether it was created.
+func (x *extractor) special(name, dst string, info os.FileInfo, policy SpecialPolicy) (bool, error) {
+	switch policy {
+	case SpecialSkip:
+		return false, nil
+	case SpecialCreate:
+	default:
+		return false, &Error{Op: "extract", Src: name, Dst: dst, Err: unsupportedType(info)}
+	}
+
+	if err := x.replace(name, dst); err != nil {
+		return false, err
+	}
+
+	if err := mknodDest(x.o.dest, dst, info); err != nil {
+		return false, &Error{Op: "Mknod", Src: name, Dst: dst, Err: err}
+	}
+
+	if err := x.o.dest.Chmod(dst, x.o.mode(info.Mode())); err != nil {
+		return false, &Error{Op: "Chmod", Src: name, Dst: dst, Err: err}
+	}
+
+	return true, wrapError("Chtimes", name, dst, x.o.setTimes(dst, info))
+}
+
+// done records the outcome of extracting one entry and reports whether
+// extraction should stop with the returned error.
+func (x *extractor) done(written bool, err error) error {
+	if err != nil {
+		if x.o.fail(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if written {
+		x.o.copied++
+	}
+
+	return nil
+}
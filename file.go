@@ -4,8 +4,6 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-
-	"github.com/pkg/errors"
 )
 
 type file struct {
@@ -19,57 +17,139 @@ func newFile(path string, fi os.FileInfo) file {
 // copyTo copies the f.path file to dst location, creating all parent directories
 // along the way. This means that directories that did not exist before
 // will exist after copying.
-func (f file) copyTo(dst string, linkOrCopy bool) error {
+func (f file) copyTo(dst string, o *options) error {
 	// make any parent directories. Assume os.ModePerm
-	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
-		return errors.Wrapf(err, "MkdirAll(%s,%s)", filepath.Dir(dst), os.ModePerm.String())
+	if err := o.mkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return &Error{Op: "MkdirAll", Src: f.path, Dst: dst, Err: err}
+	}
+
+	fns := o.transformsFor(f)
+	transformed := len(fns) > 0
+
+	// continue a copy recorded in the journal by an earlier run
+	if o.journal != nil && !transformed {
+		if resumed, err := f.resume(dst, o); resumed || err != nil {
+			return err
+		}
 	}
 
 	// If the file already exists, check to see if its the same file.  If not, remove it.
-	dstInfo, err := os.Stat(dst)
-	if err == nil {
-		if linkOrCopy && os.SameFile(f.info, dstInfo) {
+	dstInfo, err := o.dest.Lstat(dst)
+	if err == nil && os.SameFile(f.info, dstInfo) {
+		if o.linkOrCopy && !transformed {
+			o.debug("destination is already linked", f.path, dst)
+			o.emit(Event{Type: EventFileLinked, Src: f.path, Dst: dst, Info: f.info})
 			return nil
 		}
+
+		// removing dst would remove the source
+		return &Error{Op: "Stat", Src: f.path, Dst: dst, Err: ErrSameFile}
 	}
 
-	if err = os.Remove(dst); err != nil && !os.IsNotExist(err) {
-		return errors.Wrapf(err, "Remove(%s)", dst)
+	if err = o.dest.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return &Error{Op: "Remove", Src: f.path, Dst: dst, Err: err}
+	} else if err == nil {
+		o.debug("removed existing destination", f.path, dst)
 	}
 
-	if linkOrCopy {
+	if o.linkOrCopy && !transformed {
 		// linkOrCopy is set, which means attempt a link first
-		if err = os.Link(f.path, dst); err == nil {
+		if err = o.dest.Link(f.path, dst); err == nil {
 			// successfully linked, return from function
+			o.debug("linked file", f.path, dst)
+			o.emit(Event{Type: EventFileLinked, Src: f.path, Dst: dst, Info: f.info})
 			return nil
 		} // link failed, continue to copy
+
+		o.debug("link failed, falling back to copy", f.path, dst, "err", err)
 	}
 
 	// create dst file for write
-	df, err := os.Create(dst)
+	df, err := o.dest.Create(dst)
 	if err != nil {
-		return errors.Wrapf(err, "Create(%s)", dst)
+		return &Error{Op: "Create", Src: f.path, Dst: dst, Err: err}
 	}
 
 	defer closeFile(df)
 
 	// change dst file to have src mode
-	if err = os.Chmod(df.Name(), f.info.Mode()); err != nil {
-		return errors.Wrapf(err, "Chmod(%s,%s)", df.Name(), f.info.Mode().String())
+	stop := o.timer(phaseMetadata)
+	err = o.dest.Chmod(dst, o.fileMode(f, fns))
+	stop()
+
+	if err != nil {
+		return &Error{Op: "Chmod", Src: f.path, Dst: dst, Err: err}
 	}
 
 	// open source file for read
-	sf, err := os.Open(f.path)
+	sf, err := open(o.fsys, f.path)
 	if err != nil {
-		return errors.Wrapf(err, "Open(%s)", f.path)
+		return &Error{Op: "Open", Src: f.path, Dst: dst, Err: err}
 	}
 
 	defer closeFile(sf)
 
+	if !transformed {
+		return f.write(dst, df, sf, 0, o)
+	}
+
+	r, closeAll, err := transformReader(f.path, sf, fns)
+	if err != nil {
+		return &Error{Op: "Transform", Src: f.path, Dst: dst, Err: err}
+	}
+
+	defer closeAll()
+
+	return f.write(dst, df, r, 0, o)
+}
+
+// write copies the rest of sf to df, which already holds the first offset
+// bytes of the file at dst, and finishes dst.
+func (f file) write(dst string, df io.Writer, sf io.Reader, offset int64, o *options) error {
+	var w io.Writer = df
+	if o.limiter != nil {
+		w = limitedWriter{w: w, l: o.limiter}
+	}
+
+	if o.journal != nil {
+		w = &checkpointWriter{w: w, f: df, j: o.journal, dst: dst, info: f.info, offset: offset}
+	}
+
 	// copy contents
-	_, err = io.Copy(df, sf)
+	stop := o.timer(phaseData)
+	n, err := io.Copy(w, sf)
+	stop()
+
+	if err != nil {
+		return &Error{Op: "Copy", Src: f.path, Dst: dst, Err: err}
+	}
+
+	// set dst file times to match src
+	stop = o.timer(phaseMetadata)
+	err = o.setTimes(dst, f.info)
+	stop()
+
+	if err != nil {
+		return &Error{Op: "Chtimes", Src: f.path, Dst: dst, Err: err}
+	}
+
+	if err = o.syncFile(dst, df); err != nil {
+		return &Error{Op: "Fsync", Src: f.path, Dst: dst, Err: err}
+	}
+
+	if o.journal != nil {
+		if err = o.journal.done(dst, f.info, df); err != nil {
+			return &Error{Op: "journal", Src: f.path, Dst: dst, Err: err}
+		}
+	}
+
+	o.emit(Event{Type: EventFileCopied, Src: f.path, Dst: dst, Info: f.info, Bytes: n})
+
+	return nil
+}
 
-	return errors.Wrapf(err, "Copy(%s,%s)", df.Name(), sf.Name())
+func (f file) Kind() Kind {
+	return KindFile
 }
 
 func (f file) String() string {
@@ -54,7 +54,7 @@ func TestFileCopyToError(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := fo.copyTo("nowhere", false); err == nil {
+	if err := fo.copyTo(filepath.Join(t.TempDir(), "nowhere"), newOptions(false, nil)); err == nil {
 		t.Error("expected error when file did not exist but no error was returned")
 	}
 }
@@ -0,0 +1,101 @@
+package copy
+
+import (
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ReadLinkFS is implemented by file systems that can report and read
+// symbolic links. When the fs.FS given to FromFS implements it, symlinks are
+// copied as links rather than followed.
+type ReadLinkFS interface {
+	fs.FS
+
+	// ReadLink returns the destination of the named symbolic link.
+	ReadLink(name string) (string, error)
+	// Lstat returns a FileInfo describing the named file without
+	// following a final symbolic link.
+	Lstat(name string) (fs.FileInfo, error)
+}
+
+// FromFS copies root from fsys to the dst path on disk. It supports the same
+// options as All. Paths given to the Filter are slash-separated fsys paths.
+func FromFS(fsys fs.FS, root, dst string, opts ...Option) error {
+	o := newOptions(false, opts)
+	o.fsys = fsys
+
+	return copyAll(root, dst, o)
+}
+
+// lstat describes the file at name in fsys, or on disk if fsys is nil.
+func lstat(fsys fs.FS, name string) (os.FileInfo, error) {
+	switch fsys := fsys.(type) {
+	case nil:
+		return os.Lstat(name)
+	case ReadLinkFS:
+		return fsys.Lstat(name)
+	default:
+		return fs.Stat(fsys, name)
+	}
+}
+
+// readDir returns the entries of the directory name in fsys, or on disk if
+// fsys is nil, sorted by name.
+func readDir(fsys fs.FS, name string) ([]os.FileInfo, error) {
+	if fsys == nil {
+		return ioutil.ReadDir(name)
+	}
+
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// open opens the file name in fsys, or on disk if fsys is nil, for reading.
+func open(fsys fs.FS, name string) (io.ReadCloser, error) {
+	if fsys == nil {
+		return os.Open(name)
+	}
+
+	return fsys.Open(name)
+}
+
+// readlink returns the destination of the symlink name in fsys, or on disk
+// if fsys is nil.
+func readlink(fsys fs.FS, name string) (string, error) {
+	switch fsys := fsys.(type) {
+	case nil:
+		return os.Readlink(name)
+	case ReadLinkFS:
+		return fsys.ReadLink(name)
+	default:
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+}
+
+// join joins a source directory and child name using the separator of fsys.
+func join(fsys fs.FS, dir, name string) string {
+	if fsys == nil {
+		return filepath.Join(dir, name)
+	}
+
+	return path.Join(dir, name)
+}
@@ -0,0 +1,84 @@
+package copy
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// linkDirFS is an os.DirFS that also implements ReadLinkFS.
+type linkDirFS struct {
+	fs.FS
+	dir string
+}
+
+func (l linkDirFS) ReadLink(name string) (string, error) {
+	return os.Readlink(filepath.Join(l.dir, filepath.FromSlash(name)))
+}
+
+func (l linkDirFS) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(filepath.Join(l.dir, filepath.FromSlash(name)))
+}
+
+func TestFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"root/file1":         {Data: []byte("test"), Mode: 0644},
+		"root/sub/file2":     {Data: []byte("test2"), Mode: 0600},
+		"root/sub/excluded":  {Data: []byte("nope"), Mode: 0644},
+		"other/not-included": {Data: []byte("nope"), Mode: 0644},
+	}
+	dst := filepath.Join(mustCreateTestDirectory(t, "", "fromfs"), "dst")
+
+	exclude := func(path string, _ os.FileInfo) bool {
+		return path != "root/sub/excluded"
+	}
+
+	if err := FromFS(fsys, "root", dst, Filter(exclude)); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dst, "sub", "file2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "test2" {
+		t.Errorf("expected 'test2' but got '%s'", b)
+	}
+
+	fi, err := os.Lstat(filepath.Join(dst, "sub", "file2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fi.Mode() != 0600 {
+		t.Errorf("expected mode %v but got %v", os.FileMode(0600), fi.Mode())
+	}
+
+	mustExist(t, filepath.Join(dst, "file1"))
+	mustNotExist(t, filepath.Join(dst, "sub", "excluded"))
+}
+
+func TestFromFSLinks(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "fromfs")
+	src := mustCreateTestDirectory(t, d, "src")
+	mustCreateTestFile(t, filepath.Join(src, "file1"))
+	mustCreateTestLink(t, filepath.Join(src, "link1"), "file1")
+	dst := filepath.Join(d, "dst")
+
+	if err := FromFS(linkDirFS{os.DirFS(src), src}, ".", dst); err != nil {
+		t.Fatal(err)
+	}
+
+	mustBeSameFile(t, filepath.Join(src, "file1"), filepath.Join(dst, "file1"))
+	mustBeSameFile(t, filepath.Join(src, "link1"), filepath.Join(dst, "link1"))
+}
+
+func TestFromFSError(t *testing.T) {
+	if err := FromFS(fstest.MapFS{}, "none", "none"); err == nil {
+		t.Error("expected error when file does not exist but no error was returned")
+	}
+}
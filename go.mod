@@ -1,5 +1,3 @@
 module github.com/matthewrsj/copy
 
-go 1.15
-
-require github.com/pkg/errors v0.9.1
+go 1.16
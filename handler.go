@@ -0,0 +1,100 @@
+package copy
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Matcher reports whether a Handler applies to obj.
+type Matcher func(obj Object) bool
+
+// MatchKind matches objects of any of the given kinds.
+func MatchKind(kinds ...Kind) Matcher {
+	return func(obj Object) bool {
+		for _, k := range kinds {
+			if obj.Kind() == k {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// MatchMode matches objects whose mode has every bit in bits set, such as
+// os.ModeSetuid or 0100 for files executable by their owner.
+func MatchMode(bits os.FileMode) Matcher {
+	return func(obj Object) bool {
+		return obj.Info().Mode()&bits == bits
+	}
+}
+
+// MatchName matches objects whose base name matches the filepath.Match
+// pattern. A malformed pattern matches nothing.
+func MatchName(pattern string) Matcher {
+	return func(obj Object) bool {
+		ok, err := filepath.Match(pattern, filepath.Base(obj.Path()))
+		return err == nil && ok
+	}
+}
+
+// Handler copies obj to dst in place of the built-in copy. copyDefault
+// runs the built-in copy of obj to any destination, so a handler can
+// delegate to it, for example after writing dst itself fails. A handler for
+// a directory that does not delegate is responsible for its children.
+type Handler func(obj Object, dst string, copyDefault func(dst string) error) error
+
+// Registry holds handlers that replace the built-in copy for matching
+// objects. Handlers are tried in the order they were registered and the
+// first match is used. A Registry may be shared by several copies but must
+// not be changed while one is running.
+type Registry struct {
+	handlers []registeredHandler
+}
+
+type registeredHandler struct {
+	match   Matcher
+	handler Handler
+}
+
+// Register adds h for objects matched by m.
+func (r *Registry) Register(m Matcher, h Handler) {
+	r.handlers = append(r.handlers, registeredHandler{match: m, handler: h})
+}
+
+// lookup returns the handler for obj or nil if none matches.
+func (r *Registry) lookup(obj Object) Handler {
+	for _, h := range r.handlers {
+		if h.match(obj) {
+			return h.handler
+		}
+	}
+
+	return nil
+}
+
+// Handlers copies objects matched by a handler in r with that handler
+// instead of the built-in copy. It applies to every object copied by All,
+// LinkOrCopy and FromFS, including the top-level source. Errors returned by
+// a handler are returned as is.
+func Handlers(r *Registry) Option {
+	return func(o *options) {
+		o.handlers = r
+	}
+}
+
+// handle copies obj to dst with its registered handler, or the built-in
+// copy if there is none.
+func (o *options) handle(obj copyObject, dst string) error {
+	if o.handlers != nil {
+		if h := o.handlers.lookup(obj); h != nil {
+			o.debug("copying with registered handler", obj.Path(), dst)
+
+			return h(obj, dst, func(dst string) error {
+				return obj.copyTo(dst, o)
+			})
+		}
+	}
+
+	return obj.copyTo(dst, o)
+}
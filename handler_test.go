@@ -0,0 +1,96 @@
+package copy
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandlers(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "handler")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+
+	var r Registry
+	r.Register(MatchName("file1"), func(obj Object, dst string, _ func(string) error) error {
+		return ioutil.WriteFile(dst, []byte("generated"), 0644)
+	})
+
+	var delegated []string
+	r.Register(MatchKind(KindFile), func(obj Object, dst string, copyDefault func(string) error) error {
+		delegated = append(delegated, filepath.Base(dst))
+		return copyDefault(dst)
+	})
+
+	if err := All(src, dst, Handlers(&r)); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dst, "sub", "file1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "generated" {
+		t.Errorf("expected handler content but got '%s'", b)
+	}
+
+	if len(delegated) != 1 || delegated[0] != "file2" {
+		t.Errorf("expected only file2 to be delegated but got %v", delegated)
+	}
+
+	mustBeSameFile(t, filepath.Join(src, "file2"), filepath.Join(dst, "file2"))
+	mustBeSameFile(t, filepath.Join(src, "link1"), filepath.Join(dst, "link1"))
+}
+
+func TestHandlersError(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "handler")
+	src := mustCreateTestTree(t, d)
+	errHandler := errors.New("handler failed")
+
+	var r Registry
+	r.Register(MatchKind(KindSymlink), func(Object, string, func(string) error) error {
+		return errHandler
+	})
+
+	if err := All(src, filepath.Join(d, "dst"), Handlers(&r)); !errors.Is(err, errHandler) {
+		t.Errorf("expected handler error but got %v", err)
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "handler")
+	exe := mustCreateTestFile(t, filepath.Join(d, "run.sh")).Name()
+
+	if err := os.Chmod(exe, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := Stat(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		match    Matcher
+		expected bool
+	}{
+		{"kind", MatchKind(KindDirectory, KindFile), true},
+		{"other kind", MatchKind(KindSymlink), false},
+		{"mode", MatchMode(0100), true},
+		{"other mode", MatchMode(os.ModeSetuid), false},
+		{"name", MatchName("*.sh"), true},
+		{"other name", MatchName("*.py"), false},
+		{"bad pattern", MatchName("["), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.match(obj) != tc.expected {
+				t.Errorf("expected match to be %t", tc.expected)
+			}
+		})
+	}
+}
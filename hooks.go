@@ -0,0 +1,61 @@
+package copy
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrSkip is returned by a BeforeCopy hook to leave an entry, and
+// everything below it, out of the copy.
+var ErrSkip = errors.New("skip this entry")
+
+// BeforeCopy sets a function called before each entry is copied from src
+// to dst, with info describing the source. Returning ErrSkip leaves the
+// entry out of the copy. Returning any other error aborts the copy, even
+// with ContinueOnError, and is returned wrapped in an *Error.
+func BeforeCopy(fn func(src, dst string, info os.FileInfo) error) Option {
+	return func(o *options) {
+		o.beforeCopy = fn
+	}
+}
+
+// AfterCopy sets a function called after each entry is copied from src to
+// dst, or failed to be copied, with info describing the source and err the
+// outcome. The error fn returns replaces the outcome, so it should return
+// err to keep it. The hook for a directory is called after its children
+// are copied. Entries skipped by the Filter or BeforeCopy are not reported.
+func AfterCopy(fn func(src, dst string, info os.FileInfo, err error) error) Option {
+	return func(o *options) {
+		o.afterCopy = fn
+	}
+}
+
+// before runs the BeforeCopy hook for obj.
+func (o *options) before(obj Object, dst string) error {
+	if o.beforeCopy == nil {
+		return nil
+	}
+
+	err := o.beforeCopy(obj.Path(), dst, obj.Info())
+	if err == nil {
+		return nil
+	}
+
+	if err == ErrSkip {
+		o.debug("skipped by BeforeCopy", obj.Path(), dst)
+		return err
+	}
+
+	o.aborted = true
+
+	return &Error{Op: "BeforeCopy", Src: obj.Path(), Dst: dst, Err: err}
+}
+
+// after runs the AfterCopy hook for obj with the outcome err.
+func (o *options) after(obj Object, dst string, err error) error {
+	if o.afterCopy == nil {
+		return err
+	}
+
+	return o.afterCopy(obj.Path(), dst, obj.Info(), err)
+}
@@ -0,0 +1,98 @@
+package copy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBeforeCopySkip(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "hooks")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+
+	skipSub := func(src, _ string, info os.FileInfo) error {
+		if info.IsDir() && info.Name() == "sub" {
+			return ErrSkip
+		}
+
+		return nil
+	}
+
+	if err := All(src, dst, BeforeCopy(skipSub)); err != nil {
+		t.Fatal(err)
+	}
+
+	mustNotExist(t, filepath.Join(dst, "sub"))
+	mustBeSameFile(t, filepath.Join(src, "file2"), filepath.Join(dst, "file2"))
+}
+
+func TestBeforeCopyAbort(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "hooks")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+	errRefused := errors.New("refused")
+
+	refuse := func(src, _ string, info os.FileInfo) error {
+		if info.Name() == "file2" {
+			return errRefused
+		}
+
+		return nil
+	}
+
+	err := All(src, dst, BeforeCopy(refuse), ContinueOnError())
+
+	var e *Error
+	if !errors.As(err, &e) || e.Op != "BeforeCopy" || !errors.Is(err, errRefused) {
+		t.Fatalf("expected BeforeCopy error but got %v", err)
+	}
+
+	// entries after the refused one are not copied
+	mustNotExist(t, filepath.Join(dst, "link1"))
+}
+
+func TestAfterCopy(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "hooks")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+	errAudit := errors.New("audit failed")
+
+	var seen []string
+	audit := func(src, dst string, info os.FileInfo, err error) error {
+		if _, statErr := os.Lstat(dst); statErr != nil {
+			t.Errorf("expected %s to exist after it was copied", dst)
+		}
+
+		name, _ := filepath.Rel(d, src)
+		if err != nil {
+			name += " failed"
+		}
+		seen = append(seen, filepath.ToSlash(name))
+
+		if info.Name() == "file1" {
+			return errAudit
+		}
+
+		return err
+	}
+
+	if err := All(src, dst, AfterCopy(audit)); !errors.Is(err, errAudit) {
+		t.Fatalf("expected hook error but got %v", err)
+	}
+
+	// directories are reported after their children with their outcome
+	base := filepath.Base(src)
+	expected := []string{
+		base + "/file2",
+		base + "/link1",
+		base + "/sub/file1",
+		base + "/sub failed",
+		base + " failed",
+	}
+	if !reflect.DeepEqual(seen, expected) {
+		t.Errorf("expected hooks %v but got %v", expected, seen)
+	}
+}
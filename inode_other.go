@@ -0,0 +1,23 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package copy
+
+import (
+	"os"
+)
+
+// inode is not supported on this platform.
+func inode(os.FileInfo) (id fileID, nlink uint64, ok bool) {
+	return fileID{}, 0, false
+}
+
+// owner is not supported on this platform.
+func owner(os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
+
+// devNumbers is not supported on this platform.
+func devNumbers(os.FileInfo) (major, minor uint32, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,54 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package copy
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// inode returns the identity of the file described by fi and its number of
+// hard links. ok is false if fi does not carry that information.
+func inode(fi os.FileInfo) (id fileID, nlink uint64, ok bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, 0, false
+	}
+
+	return fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}, uint64(st.Nlink), true
+}
+
+// owner returns the user and group ids of the file described by fi. ok is
+// false if fi does not carry that information.
+func owner(fi os.FileInfo) (uid, gid int, ok bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return int(st.Uid), int(st.Gid), true
+}
+
+// devNumbers returns the major and minor numbers of the device described
+// by fi. ok is false if fi does not carry that information or the device
+// number encoding of the platform is unknown.
+func devNumbers(fi os.FileInfo) (major, minor uint32, ok bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+
+	dev := uint64(st.Rdev)
+
+	switch runtime.GOOS {
+	case "linux":
+		return uint32((dev&0x00000000000fff00)>>8 | (dev&0xfffff00000000000)>>32),
+			uint32(dev&0x00000000000000ff | (dev&0x00000ffffff00000)>>12), true
+	case "darwin":
+		return uint32(dev >> 24 & 0xff), uint32(dev & 0xffffff), true
+	default:
+		return 0, 0, false
+	}
+}
@@ -2,8 +2,6 @@ package copy
 
 import (
 	"os"
-
-	"github.com/pkg/errors"
 )
 
 type link struct {
@@ -15,26 +13,48 @@ func newLink(path string, fi os.FileInfo) link {
 }
 
 // copyTo copies a symlink by replicating the l.path symlink at dst
-func (l link) copyTo(dst string, linkOrCopy bool) error {
-	src, err := os.Readlink(l.path)
+func (l link) copyTo(dst string, o *options) error {
+	src, err := readlink(o.fsys, l.path)
 	if err != nil {
-		return errors.Wrapf(err, "ReadLink(%s)", l.path)
+		return &Error{Op: "Readlink", Src: l.path, Dst: dst, Err: err}
 	}
 
 	// If the link already exists, check to see if it's the same link. If not, remove it.
-	dstInfo, err := os.Stat(dst)
+	dstInfo, err := o.dest.Lstat(dst)
 	if err == nil && dstInfo.Mode()&os.ModeSymlink != 0 {
-		dstLink, err := os.Readlink(dst)
+		dstLink, err := o.dest.Readlink(dst)
 		if err == nil && dstLink == src {
-			return nil
+			o.debug("destination is already the same symlink", l.path, dst, "target", src)
+			return l.created(dst, o)
 		}
 	}
 
-	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
-		return errors.Wrapf(err, "Remove(%s)", dst)
+	if err := o.dest.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return &Error{Op: "Remove", Src: l.path, Dst: dst, Err: err}
+	} else if err == nil {
+		o.debug("removed existing destination", l.path, dst)
+	}
+
+	if err := o.dest.Symlink(src, dst); err != nil {
+		return &Error{Op: "Symlink", Src: l.path, Dst: dst, Err: err}
+	}
+
+	return l.created(dst, o)
+}
+
+// created finishes the symlink at dst once it exists.
+func (l link) created(dst string, o *options) error {
+	if err := o.setLinkTimes(dst); err != nil {
+		return &Error{Op: "Lchtimes", Src: l.path, Dst: dst, Err: err}
 	}
 
-	return errors.Wrapf(os.Symlink(src, dst), "Symlink(%s,%s)", src, dst)
+	o.emit(Event{Type: EventSymlinkCreated, Src: l.path, Dst: dst, Info: l.info})
+
+	return nil
+}
+
+func (l link) Kind() Kind {
+	return KindSymlink
 }
 
 func (l link) String() string {
@@ -46,7 +46,7 @@ func TestLinkInfo(t *testing.T) {
 
 func TestLinkCopyToError(t *testing.T) {
 	l := link{base{path: "foo"}}
-	if err := l.copyTo("nowhere", false); err == nil {
+	if err := l.copyTo(filepath.Join(t.TempDir(), "nowhere"), newOptions(false, nil)); err == nil {
 		t.Error("expected error when file did not exist but no error was returned")
 	}
 }
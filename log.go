@@ -0,0 +1,27 @@
+package copy
+
+// Logger receives debug records of the decisions a copy makes. Args are
+// alternating keys and values, starting with "src" and "dst" attributes
+// for the entry involved. *slog.Logger satisfies Logger.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+}
+
+// Log records each decision a copy makes, such as a failed link attempt
+// falling back to a copy, a destination being removed or a directory mode
+// being widened temporarily, at debug level on l.
+func Log(l Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// debug logs msg for the entry copied from src to dst with the extra
+// key-value pairs in args.
+func (o *options) debug(msg, src, dst string, args ...interface{}) {
+	if o.logger == nil {
+		return
+	}
+
+	o.logger.Debug(msg, append([]interface{}{"src", src, "dst", dst}, args...)...)
+}
@@ -0,0 +1,64 @@
+package copy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordLogger records each debug message with its attributes.
+type recordLogger struct {
+	records []string
+}
+
+func (l *recordLogger) Debug(msg string, args ...interface{}) {
+	l.records = append(l.records, msg+" "+strings.TrimSpace(fmt.Sprintln(args...)))
+}
+
+func (l *recordLogger) mustHave(t *testing.T, prefix string) {
+	t.Helper()
+
+	for _, r := range l.records {
+		if strings.HasPrefix(r, prefix) {
+			return
+		}
+	}
+
+	t.Errorf("expected a record starting with '%s' in %q", prefix, l.records)
+}
+
+func TestLogLinkFallback(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "log")
+	src := mustCreateTestFile(t, filepath.Join(d, "src")).Name()
+	dst := filepath.Join(d, "dst")
+
+	var l recordLogger
+	if err := LinkOrCopy(src, dst, Destination(NewMemFS()), Log(&l)); err != nil {
+		t.Fatal(err)
+	}
+
+	l.mustHave(t, "link failed, falling back to copy src "+src+" dst "+dst+" err ")
+}
+
+func TestLogDecisions(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "log")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+
+	mustCreateTestFile(t, filepath.Join(mustMkdirAll(t, dst), "file2"))
+
+	if err := os.Chmod(filepath.Join(src, "sub"), 0555); err != nil {
+		t.Fatal(err)
+	}
+
+	var l recordLogger
+	if err := All(src, dst, Log(&l)); err != nil {
+		t.Fatal(err)
+	}
+
+	l.mustHave(t, "removed existing destination src "+filepath.Join(src, "file2"))
+	l.mustHave(t, "widened directory mode to copy children src "+filepath.Join(src, "sub"))
+	l.mustHave(t, "restored directory mode src "+filepath.Join(src, "sub"))
+}
@@ -0,0 +1,172 @@
+package copy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPathCollision is returned when MapPath maps two sources to the same
+// destination.
+var ErrPathCollision = errors.New("sources map to the same destination")
+
+// ErrInvalidPath is returned when MapPath maps a source outside of the
+// top-level destination.
+var ErrInvalidPath = errors.New("mapped path is outside of the destination")
+
+// MapPath sets a function that returns the destination of each entry
+// below the top-level source, for example to flatten or rename
+// directories. It is called with the slash-separated path of the entry
+// relative to the top-level source and returns the slash-separated path of
+// its copy relative to the top-level destination, or false to skip the
+// entry and, for a directory, everything in it.
+//
+// The children of a directory are mapped on their own, so a directory
+// mapped to "." merges its content into the top-level destination.
+// Directories may be mapped to the same destination, but mapping any other
+// entry to a destination that is already taken fails with
+// ErrPathCollision. With Mirror, a destination entry is kept if any entry
+// is mapped to it or below it.
+func MapPath(fn func(rel string) (string, bool)) Option {
+	return func(o *options) {
+		o.mapPath = fn
+	}
+}
+
+// mapping records the source mapped to a destination.
+type mapping struct {
+	src string
+	dir bool
+}
+
+// startMapping records that the top-level src is copied to dst. With
+// Mirror, it maps the whole tree up front, so that no directory is pruned
+// of an entry that a later source directory maps into it.
+func (o *options) startMapping(src, dst string, info os.FileInfo) {
+	if o.mapPath == nil {
+		return
+	}
+
+	o.srcRoot = src
+	o.dstRoot = filepath.Clean(dst)
+	o.mapped = map[string]mapping{o.dstRoot: {src: src, dir: info.IsDir()}}
+	o.planned = make(map[string]bool)
+
+	if o.mirror && info.IsDir() {
+		stop := o.timer(phaseMirror)
+		o.planMapping(src)
+		stop()
+	}
+}
+
+// planMapping records the destinations of every entry below the directory
+// at path. Errors are left for the copy to report.
+func (o *options) planMapping(path string) {
+	children, err := readDir(o.fsys, path)
+	if err != nil {
+		return
+	}
+
+	for _, child := range children {
+		childSrc := join(o.fsys, path, child.Name())
+		if o.isNestedDst(child) || !o.included(childSrc, child) {
+			continue
+		}
+
+		childDst, ok, err := o.mapDestination(childSrc, child)
+		if err != nil || !ok {
+			continue
+		}
+
+		o.plan(childDst)
+
+		if child.IsDir() {
+			o.planMapping(childSrc)
+		}
+	}
+}
+
+// destination returns the path of the copy of the entry at path, described
+// by info, in the directory copied to dst. It reports false if MapPath
+// skips the entry.
+func (o *options) destination(dst, path string, info os.FileInfo) (string, bool, error) {
+	if o.mapPath == nil {
+		name, err := o.renderName(path, info.Name(), info)
+		if err != nil {
+			return "", false, &Error{Op: "Template", Src: path, Dst: filepath.Join(dst, info.Name()), Err: err}
+		}
+
+		return filepath.Join(dst, name), true, nil
+	}
+
+	childDst, ok, err := o.mapDestination(path, info)
+	if err != nil || !ok {
+		return "", false, err
+	}
+
+	if m, ok := o.mapped[childDst]; ok && !(m.dir && info.IsDir()) {
+		return "", false, &Error{Op: "MapPath", Src: path, Dst: childDst, Err: fmt.Errorf("%w as %s", ErrPathCollision, m.src)}
+	}
+
+	o.mapped[childDst] = mapping{src: path, dir: info.IsDir()}
+	o.plan(childDst)
+
+	return childDst, true, nil
+}
+
+// mapDestination returns the destination MapPath maps the entry at path,
+// described by info, to. It reports false if MapPath skips the entry.
+func (o *options) mapDestination(path string, info os.FileInfo) (string, bool, error) {
+	rel, ok := o.mapPath(o.relSrc(path))
+	if !ok {
+		return "", false, nil
+	}
+
+	rel = filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false, &Error{Op: "MapPath", Src: path, Dst: rel, Err: ErrInvalidPath}
+	}
+
+	dir, name := filepath.Split(rel)
+
+	name, err := o.renderName(path, name, info)
+	if err != nil {
+		return "", false, &Error{Op: "Template", Src: path, Dst: filepath.Join(o.dstRoot, rel), Err: err}
+	}
+
+	return filepath.Join(o.dstRoot, dir, name), true, nil
+}
+
+// plan records that an entry is mapped to dst, which keeps dst and the
+// directories above it from being pruned by Mirror.
+func (o *options) plan(dst string) {
+	for p := dst; len(p) > len(o.dstRoot) && !o.planned[p]; p = filepath.Dir(p) {
+		o.planned[p] = true
+	}
+}
+
+// relSrc returns the slash-separated path of path relative to the
+// top-level source.
+func (o *options) relSrc(path string) string {
+	if o.fsys != nil {
+		if o.srcRoot == "." {
+			return path
+		}
+
+		return strings.TrimPrefix(path, o.srcRoot+"/")
+	}
+
+	rel, err := filepath.Rel(o.srcRoot, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// mappedTo reports whether an entry is mapped to path or below it.
+func (o *options) mappedTo(path string) bool {
+	return o.planned[path]
+}
@@ -0,0 +1,151 @@
+package copy
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// flatten maps v1/name and v2/name to name.
+func flatten(rel string) (string, bool) {
+	dir, name := path.Split(rel)
+	if dir == "" && strings.HasPrefix(name, "v") {
+		return ".", true
+	}
+
+	return name, true
+}
+
+func TestMapPath(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "mappath")
+	src := filepath.Join(d, "src")
+	mustWriteFile(t, filepath.Join(src, "Docs", "README"), "readme")
+	mustWriteFile(t, filepath.Join(src, "tmp", "scratch"), "scratch")
+
+	mapping := func(rel string) (string, bool) {
+		if rel == "tmp" {
+			return "", false
+		}
+
+		return "prefix-" + strings.ToLower(rel), true
+	}
+
+	dst := filepath.Join(d, "dst")
+	if err := All(src, dst, MapPath(mapping)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(mustReadFile(t, filepath.Join(dst, "prefix-docs", "readme"))); got != "readme" {
+		t.Errorf("expected mapped file but got %q", got)
+	}
+	mustNotExist(t, filepath.Join(dst, "prefix-tmp"))
+	mustNotExist(t, filepath.Join(dst, "tmp"))
+}
+
+func TestMapPathFlatten(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "mappath")
+	src := filepath.Join(d, "src")
+	mustWriteFile(t, filepath.Join(src, "v1", "a"), "a")
+	mustWriteFile(t, filepath.Join(src, "v2", "b"), "b")
+
+	dst := mustMkdirAll(t, filepath.Join(d, "dst"))
+	mustCreateTestFile(t, filepath.Join(dst, "stale"))
+
+	if err := All(src, dst, MapPath(flatten), Mirror()); err != nil {
+		t.Fatal(err)
+	}
+
+	mustExist(t, filepath.Join(dst, "a"))
+	mustExist(t, filepath.Join(dst, "b"))
+	mustNotExist(t, filepath.Join(dst, "v1"))
+	mustNotExist(t, filepath.Join(dst, "stale"))
+}
+
+func TestMapPathMirrorMerged(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "mappath")
+	src := filepath.Join(d, "src")
+	mustWriteFile(t, filepath.Join(src, "v1", "a"), "a")
+	mustWriteFile(t, filepath.Join(src, "v2", "b"), "b")
+
+	dst := filepath.Join(d, "dst")
+	if err := All(src, dst, MapPath(flatten)); err != nil {
+		t.Fatal(err)
+	}
+
+	// v1 is mirrored before v2 maps b into the same directory
+	var wouldRemove []string
+	dryRun := func(path string, _ os.FileInfo) {
+		wouldRemove = append(wouldRemove, path)
+	}
+
+	if err := All(src, dst, MapPath(flatten), MirrorDryRun(dryRun), MirrorLimit(1)); err != nil {
+		t.Fatal(err)
+	}
+	if len(wouldRemove) != 0 {
+		t.Errorf("expected nothing to be removed but got %v", wouldRemove)
+	}
+
+	events, err := mustCollectEvents(t, func(opt Option) error {
+		return All(src, dst, MapPath(flatten), Mirror(), opt)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range events {
+		if e.Type == EventRemoved {
+			t.Errorf("expected no removals but %s was removed", e.Dst)
+		}
+	}
+
+	mustExist(t, filepath.Join(dst, "a"))
+	mustExist(t, filepath.Join(dst, "b"))
+}
+
+func TestMapPathCollision(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "mappath")
+	src := filepath.Join(d, "src")
+	mustWriteFile(t, filepath.Join(src, "v1", "a"), "first")
+	mustWriteFile(t, filepath.Join(src, "v2", "a"), "second")
+	mustWriteFile(t, filepath.Join(src, "v2", "b"), "b")
+
+	dst := filepath.Join(d, "dst")
+	err := All(src, dst, MapPath(flatten), ContinueOnError())
+
+	var e *Error
+	if !errors.As(err, &e) || e.Op != "MapPath" || !errors.Is(err, ErrPathCollision) {
+		t.Fatalf("expected collision error but got %v", err)
+	}
+	if e.Src != filepath.Join(src, "v2", "a") || e.Dst != filepath.Join(dst, "a") {
+		t.Errorf("expected collision of v2/a at a but got %s at %s", e.Src, e.Dst)
+	}
+	if !strings.Contains(err.Error(), filepath.Join(src, "v1", "a")) {
+		t.Errorf("expected error to name the first source but got %q", err)
+	}
+
+	if got := string(mustReadFile(t, filepath.Join(dst, "a"))); got != "first" {
+		t.Errorf("expected first source to be kept but got %q", got)
+	}
+	mustExist(t, filepath.Join(dst, "b"))
+}
+
+func TestMapPathOutsideDestination(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "mappath")
+	src := filepath.Join(d, "src")
+	mustWriteFile(t, filepath.Join(src, "file"), "file")
+
+	for _, rel := range []string{"../escape", "/abs", "."} {
+		t.Run(rel, func(t *testing.T) {
+			mapping := func(string) (string, bool) { return rel, true }
+
+			if err := All(src, filepath.Join(d, "dst"), MapPath(mapping)); err == nil {
+				t.Errorf("expected %s to be rejected", rel)
+			}
+		})
+	}
+
+	mustNotExist(t, filepath.Join(d, "escape"))
+}
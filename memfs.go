@@ -0,0 +1,328 @@
+package copy
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// MemFS is an in-memory DestFS, useful for testing and for staging a copy
+// before it is written elsewhere. Root directories such as "/" and "."
+// always exist. MemFS is safe for concurrent use.
+type MemFS struct {
+	mu    sync.Mutex
+	nodes map[string]*memNode
+}
+
+// memNode is a single inode. Hard links share the same node.
+type memNode struct {
+	mode    os.FileMode
+	modTime time.Time
+	data    []byte
+	target  string
+}
+
+// NewMemFS returns an empty MemFS.
+func NewMemFS() *MemFS {
+	return &MemFS{nodes: make(map[string]*memNode)}
+}
+
+// Mkdir creates the named directory.
+func (m *MemFS) Mkdir(name string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name, err := m.create("mkdir", name)
+	if err != nil {
+		return err
+	}
+
+	m.nodes[name] = &memNode{mode: os.ModeDir | perm.Perm(), modTime: time.Now()}
+
+	return nil
+}
+
+// Create creates or truncates the named regular file.
+func (m *MemFS) Create(name string) (io.WriteCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+
+	if n, ok := m.nodes[name]; ok {
+		if !n.mode.IsRegular() {
+			return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EISDIR}
+		}
+
+		n.data = nil
+		n.modTime = time.Now()
+
+		return &memWriter{fs: m, node: n}, nil
+	}
+
+	name, err := m.create("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &memNode{mode: 0666, modTime: time.Now()}
+	m.nodes[name] = n
+
+	return &memWriter{fs: m, node: n}, nil
+}
+
+// Symlink creates newname as a symbolic link to oldname.
+func (m *MemFS) Symlink(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	newname, err := m.create("symlink", newname)
+	if err != nil {
+		return err
+	}
+
+	m.nodes[newname] = &memNode{mode: os.ModeSymlink | 0777, modTime: time.Now(), target: oldname}
+
+	return nil
+}
+
+// Link always fails with a cross-device error. oldname is a path on the
+// source file system, which can never be linked into m, so LinkOrCopy
+// falls back to copying.
+func (m *MemFS) Link(oldname, newname string) error {
+	return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: errCrossDevice}
+}
+
+// Chmod changes the mode of the named file.
+func (m *MemFS) Chmod(name string, mode os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n, err := m.node("chmod", name)
+	if err != nil {
+		return err
+	}
+
+	n.mode = n.mode.Type() | mode&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky)
+
+	return nil
+}
+
+// Chtimes changes the modification time of the named file. MemFS does not
+// record access times.
+func (m *MemFS) Chtimes(name string, _, mtime time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n, err := m.node("chtimes", name)
+	if err != nil {
+		return err
+	}
+
+	n.modTime = mtime
+
+	return nil
+}
+
+// Lchtimes changes the modification time of the named file. MemFS never
+// follows symbolic links, so it is the same as Chtimes.
+func (m *MemFS) Lchtimes(name string, atime, mtime time.Time) error {
+	return m.Chtimes(name, atime, mtime)
+}
+
+// Remove removes the named file or empty directory.
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n, err := m.node("remove", name)
+	if err != nil {
+		return err
+	}
+
+	name = filepath.Clean(name)
+	if n.mode.IsDir() && len(m.children(name)) > 0 {
+		return &os.PathError{Op: "remove", Path: name, Err: errNotEmpty}
+	}
+
+	delete(m.nodes, name)
+
+	return nil
+}
+
+// Lstat describes the named file.
+func (m *MemFS) Lstat(name string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if isRoot(name) {
+		return memInfo{name: name, mode: os.ModeDir | 0755}, nil
+	}
+
+	n, err := m.node("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.info(filepath.Base(name)), nil
+}
+
+// Readlink returns the destination of the named symbolic link.
+func (m *MemFS) Readlink(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n, err := m.node("readlink", name)
+	if err != nil {
+		return "", err
+	}
+
+	if n.mode&os.ModeSymlink == 0 {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: syscall.EINVAL}
+	}
+
+	return n.target, nil
+}
+
+// ReadDir returns the entries of the named directory sorted by name.
+func (m *MemFS) ReadDir(name string) ([]os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if err := m.isDir("readdirent", name); err != nil {
+		return nil, err
+	}
+
+	children := m.children(name)
+	infos := make([]os.FileInfo, 0, len(children))
+
+	for _, child := range children {
+		infos = append(infos, m.nodes[child].info(filepath.Base(child)))
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+
+	return infos, nil
+}
+
+// ReadFile returns the contents of the named regular file.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n, err := m.node("read", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !n.mode.IsRegular() {
+		return nil, &os.PathError{Op: "read", Path: name, Err: syscall.EISDIR}
+	}
+
+	return append([]byte(nil), n.data...), nil
+}
+
+// node returns the node for name or an op error if it does not exist.
+func (m *MemFS) node(op, name string) (*memNode, error) {
+	n, ok := m.nodes[filepath.Clean(name)]
+	if !ok {
+		return nil, &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+
+	return n, nil
+}
+
+// create checks that name can be created and returns it cleaned.
+func (m *MemFS) create(op, name string) (string, error) {
+	name = filepath.Clean(name)
+
+	if _, ok := m.nodes[name]; ok || isRoot(name) {
+		return "", &os.PathError{Op: op, Path: name, Err: os.ErrExist}
+	}
+
+	if err := m.isDir(op, filepath.Dir(name)); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// isDir returns an error unless name is an existing directory.
+func (m *MemFS) isDir(op, name string) error {
+	if isRoot(name) {
+		return nil
+	}
+
+	n, err := m.node(op, name)
+	if err != nil {
+		return err
+	}
+
+	if !n.mode.IsDir() {
+		return &os.PathError{Op: op, Path: name, Err: syscall.ENOTDIR}
+	}
+
+	return nil
+}
+
+// children returns the paths of the direct children of dir.
+func (m *MemFS) children(dir string) []string {
+	var children []string
+
+	for name := range m.nodes {
+		if name != dir && filepath.Dir(name) == dir {
+			children = append(children, name)
+		}
+	}
+
+	return children
+}
+
+func (n *memNode) info(name string) memInfo {
+	return memInfo{name: name, mode: n.mode, modTime: n.modTime, size: int64(len(n.data))}
+}
+
+// isRoot reports whether name is a root directory of a MemFS.
+func isRoot(name string) bool {
+	return filepath.Dir(name) == name
+}
+
+// memWriter appends to the data of a MemFS file.
+type memWriter struct {
+	fs   *MemFS
+	node *memNode
+}
+
+func (w *memWriter) Write(p []byte) (int, error) {
+	w.fs.mu.Lock()
+	defer w.fs.mu.Unlock()
+
+	w.node.data = append(w.node.data, p...)
+
+	return len(p), nil
+}
+
+func (w *memWriter) Close() error {
+	return nil
+}
+
+// memInfo is the os.FileInfo of a MemFS file.
+type memInfo struct {
+	name    string
+	mode    os.FileMode
+	modTime time.Time
+	size    int64
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) Mode() os.FileMode  { return i.mode }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,82 @@
+package copy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemFSRemove(t *testing.T) {
+	m := NewMemFS()
+	if err := m.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Symlink("target", "dir/link"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Remove("dir"); err == nil {
+		t.Error("expected error when removing non-empty directory but no error was returned")
+	}
+
+	if err := removeAll(m, "dir"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Lstat("dir"); !os.IsNotExist(err) {
+		t.Errorf("expected dir to not exist but got %v", err)
+	}
+}
+
+func TestMemFSLink(t *testing.T) {
+	m := NewMemFS()
+
+	if _, err := m.Create("file"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the name of a MemFS node is not the source of a link
+	if err := m.Link("file", "hardlink"); !errors.Is(err, errCrossDevice) {
+		t.Errorf("expected cross-device error but got %v", err)
+	}
+}
+
+func TestMemFSLinkOrCopyCopies(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "memfs")
+	src := mustCreateTestFile(t, filepath.Join(d, "src")).Name()
+	dst := filepath.Join(d, "dst")
+
+	// a node at the source path must not be linked in place of the source
+	m := NewMemFS()
+	if err := mkdirAll(m, d, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	w, err := m.Create(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte("unrelated")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = LinkOrCopy(src, dst, Destination(m)); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := m.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "test" {
+		t.Errorf("expected 'test' but got '%s'", b)
+	}
+}
+
+func TestMemFSCreateError(t *testing.T) {
+	m := NewMemFS()
+	if _, err := m.Create("missing/file"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error when parent is missing but got %v", err)
+	}
+}
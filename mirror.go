@@ -0,0 +1,84 @@
+package copy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrMirrorLimit is returned when mirroring would remove more destination
+// entries than allowed by MirrorLimit.
+var ErrMirrorLimit = errors.New("mirror deletion limit exceeded")
+
+// Mirror removes entries in each destination directory that do not exist
+// in the corresponding source directory, similar to rsync --delete. A
+// destination entry is protected from removal when the Filter would exclude
+// its source path.
+func Mirror() Option {
+	return func(o *options) {
+		o.mirror = true
+	}
+}
+
+// MirrorLimit sets the maximum number of destination entries Mirror may
+// remove during one copy. Removing a directory counts as a single entry.
+// The copy fails with ErrMirrorLimit before the limit would be exceeded.
+// A limit of zero or less means no limit.
+func MirrorLimit(n int) Option {
+	return func(o *options) {
+		o.mirrorLimit = n
+	}
+}
+
+// MirrorDryRun enables Mirror in preview mode. Instead of removing
+// extraneous destination entries, fn is called with each path that would
+// have been removed. The rest of the copy proceeds as usual.
+func MirrorDryRun(fn func(path string, info os.FileInfo)) Option {
+	return func(o *options) {
+		o.mirror = true
+		o.mirrorDryRun = fn
+	}
+}
+
+// mirror removes every entry in dst that is not among the kept names of
+// the copies of the children of d.
+func (d directory) mirror(dst string, kept map[string]bool, o *options) error {
+	existing, err := o.dest.ReadDir(dst)
+	if err != nil {
+		return &Error{Op: "ReadDir", Src: d.path, Dst: dst, Err: err}
+	}
+
+	for _, entry := range existing {
+		extra := filepath.Join(dst, entry.Name())
+		if kept[entry.Name()] || o.mappedTo(extra) {
+			continue
+		}
+
+		// the filter protects excluded paths from removal
+		if !o.included(join(o.fsys, d.path, entry.Name()), entry) {
+			continue
+		}
+
+		if o.mirrorLimit > 0 && o.deleted >= o.mirrorLimit {
+			return &Error{Op: "mirror", Src: d.path, Dst: dst, Err: fmt.Errorf("%w: limit %d", ErrMirrorLimit, o.mirrorLimit)}
+		}
+
+		o.deleted++
+
+		o.debug("removing entry missing from source", d.path, extra)
+
+		if o.mirrorDryRun != nil {
+			o.mirrorDryRun(extra, entry)
+			continue
+		}
+
+		if err = removeAll(o.dest, extra); err != nil {
+			return &Error{Op: "RemoveAll", Dst: extra, Err: err}
+		}
+
+		o.emit(Event{Type: EventRemoved, Dst: extra, Info: entry})
+	}
+
+	return nil
+}
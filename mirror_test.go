@@ -0,0 +1,95 @@
+package copy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func mustExist(t *testing.T, path string) {
+	t.Helper()
+
+	if _, err := os.Lstat(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustNotExist(t *testing.T, path string) {
+	t.Helper()
+
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to not exist but got %v", path, err)
+	}
+}
+
+func mustMkdirAll(t *testing.T, path string) string {
+	t.Helper()
+
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestMirror(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "mirror")
+	src := mustCreateTestDirectory(t, d, "src")
+	mustCreateTestFile(t, filepath.Join(src, "keep"))
+
+	dst := mustMkdirAll(t, filepath.Join(d, "dst"))
+	mustCreateTestFile(t, filepath.Join(dst, "stale"))
+	mustCreateTestFile(t, filepath.Join(mustMkdirAll(t, filepath.Join(dst, "staledir")), "file"))
+	mustCreateTestFile(t, filepath.Join(dst, "protected"))
+
+	exclude := func(path string, _ os.FileInfo) bool {
+		return filepath.Base(path) != "protected"
+	}
+
+	if err := All(src, dst, Mirror(), Filter(exclude)); err != nil {
+		t.Fatal(err)
+	}
+
+	mustBeSameFile(t, filepath.Join(src, "keep"), filepath.Join(dst, "keep"))
+	mustNotExist(t, filepath.Join(dst, "stale"))
+	mustNotExist(t, filepath.Join(dst, "staledir"))
+	mustExist(t, filepath.Join(dst, "protected"))
+}
+
+func TestMirrorLimit(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "mirror")
+	src := mustCreateTestDirectory(t, d, "src")
+	dst := mustMkdirAll(t, filepath.Join(d, "dst"))
+	mustCreateTestFile(t, filepath.Join(dst, "stale1"))
+	mustCreateTestFile(t, filepath.Join(dst, "stale2"))
+
+	err := All(src, dst, Mirror(), MirrorLimit(1))
+	if !errors.Is(err, ErrMirrorLimit) {
+		t.Errorf("expected ErrMirrorLimit but got %v", err)
+	}
+}
+
+func TestMirrorDryRun(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "mirror")
+	src := mustCreateTestDirectory(t, d, "src")
+	dst := mustMkdirAll(t, filepath.Join(d, "dst"))
+	stale := filepath.Join(dst, "stale")
+	mustCreateTestFile(t, stale)
+
+	var previewed []string
+
+	preview := func(path string, _ os.FileInfo) {
+		previewed = append(previewed, path)
+	}
+
+	if err := All(src, dst, MirrorDryRun(preview)); err != nil {
+		t.Fatal(err)
+	}
+
+	mustExist(t, stale)
+
+	if len(previewed) != 1 || previewed[0] != stale {
+		t.Errorf("expected preview of [%s] but got %v", stale, previewed)
+	}
+}
@@ -0,0 +1,12 @@
+package copy
+
+import "syscall"
+
+// atFDCWD makes mknodat resolve relative paths against the working
+// directory.
+const atFDCWD = -0x2
+
+// sysMknod calls mknod(2). AIX only provides mknodat.
+func sysMknod(path string, mode uint32, dev int) error {
+	return syscall.Mknodat(atFDCWD, path, mode, dev)
+}
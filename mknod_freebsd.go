@@ -0,0 +1,8 @@
+package copy
+
+import "syscall"
+
+// sysMknod calls mknod(2). FreeBSD takes the device number as a uint64.
+func sysMknod(path string, mode uint32, dev int) error {
+	return syscall.Mknod(path, mode, uint64(dev))
+}
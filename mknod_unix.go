@@ -0,0 +1,11 @@
+//go:build darwin || dragonfly || linux || netbsd || openbsd || solaris
+// +build darwin dragonfly linux netbsd openbsd solaris
+
+package copy
+
+import "syscall"
+
+// sysMknod calls mknod(2).
+func sysMknod(path string, mode uint32, dev int) error {
+	return syscall.Mknod(path, mode, dev)
+}
@@ -0,0 +1,76 @@
+package copy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// rename renames src to dst. It is a variable so tests can simulate moves
+// across devices.
+var rename = os.Rename
+
+// Move moves src to dst. It first attempts a rename, and if src and dst are
+// on different devices it falls back to a recursive copy that preserves
+// modes and modification times. The source is only removed after the copy
+// has fully succeeded and the destination has been verified against it.
+//
+// opts configure the fallback copy, whereas a rename moves src as it is.
+// With a Destination other than OSFS, Move always copies. Options that
+// leave out or alter entries, such as Filter or Transform, make the
+// verification fail, which keeps the source.
+func Move(src, dst string, opts ...Option) error {
+	o := newOptions(false, append([]Option{PreserveTimes()}, opts...))
+
+	if onDisk(o.dest) {
+		err := rename(src, dst)
+		if err == nil || !errors.Is(err, errCrossDevice) {
+			o.closeEvents()
+			return wrapError("Rename", src, dst, err)
+		}
+	}
+
+	// rename cannot cross devices, copy instead
+	if err := copyAll(src, dst, o); err != nil {
+		return err
+	}
+
+	if err := verify(src, dst, o.dest); err != nil {
+		return err
+	}
+
+	return wrapError("RemoveAll", src, "", os.RemoveAll(src))
+}
+
+// verify checks that every entry below src exists at the same relative
+// location below dst in d with the same type, permissions and size.
+func verify(src, dst string, d DestFS) error {
+	return filepath.Walk(src, func(path string, si os.FileInfo, err error) error {
+		if err != nil {
+			return &Error{Op: "Walk", Src: path, Err: err}
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return &Error{Op: "Rel", Src: path, Err: err}
+		}
+
+		target := filepath.Join(dst, rel)
+
+		di, err := d.Lstat(target)
+		if err != nil {
+			return &Error{Op: "Lstat", Src: path, Dst: target, Err: err}
+		}
+
+		if si.Mode() != di.Mode() {
+			return &Error{Op: "verify", Src: path, Dst: target, Err: fmt.Errorf("mode %s does not match %s", di.Mode(), si.Mode())}
+		}
+
+		if si.Mode().IsRegular() && si.Size() != di.Size() {
+			return &Error{Op: "verify", Src: path, Dst: target, Err: fmt.Errorf("size %d does not match %d", di.Size(), si.Size())}
+		}
+
+		return nil
+	})
+}
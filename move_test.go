@@ -0,0 +1,124 @@
+package copy
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestMove(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "move")
+	src := mustCreateTestDirectory(t, d, "src")
+	mustCreateTestFile(t, filepath.Join(src, "file1"))
+	dst := filepath.Join(d, "dst")
+
+	if err := Move(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	mustNotExist(t, src)
+	mustExist(t, filepath.Join(dst, "file1"))
+}
+
+func TestMoveAcrossDevices(t *testing.T) {
+	rename = func(src, dst string) error {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: errCrossDevice}
+	}
+	t.Cleanup(func() { rename = os.Rename })
+
+	d := mustCreateTestDirectory(t, "", "move")
+	src := mustCreateTestDirectory(t, d, "src")
+	shared := mustMkdirAll(t, filepath.Join(src, "shared"))
+	mustCreateTestFile(t, filepath.Join(shared, "file1"))
+
+	// a group-writable directory is narrowed by the usual umask when created
+	if err := os.Chmod(shared, 0775); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(d, "dst")
+	if err := Move(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	mustNotExist(t, src)
+	mustExist(t, filepath.Join(dst, "shared", "file1"))
+
+	fi, err := os.Stat(filepath.Join(dst, "shared"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm() != 0775 {
+		t.Errorf("expected mode 0775 but got %v", fi.Mode().Perm())
+	}
+}
+
+func TestMoveOptions(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "move")
+	src := mustCreateTestDirectory(t, d, "src")
+	mustCreateTestFile(t, filepath.Join(src, "file1"))
+	dst := filepath.Join(d, "dst")
+
+	// a MemFS destination cannot be renamed into, so src is copied
+	m := NewMemFS()
+	if err := mkdirAll(m, d, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Move(src, dst, Destination(m)); err != nil {
+		t.Fatal(err)
+	}
+
+	mustNotExist(t, src)
+	if b, err := m.ReadFile(filepath.Join(dst, "file1")); err != nil || string(b) != "test" {
+		t.Errorf("expected moved file in MemFS but got %q, %v", b, err)
+	}
+}
+
+func TestMoveRenameClosesEvents(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "move")
+	src := mustCreateTestDirectory(t, d, "src")
+	dst := filepath.Join(d, "dst")
+
+	events, err := mustCollectEvents(t, func(opt Option) error {
+		return Move(src, dst, opt)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected no events for a rename but got %d", len(events))
+	}
+
+	mustExist(t, dst)
+}
+
+func TestMoveError(t *testing.T) {
+	if err := Move("none", "none"); err == nil {
+		t.Error("expected error when file does not exist but no error was returned")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "verify")
+	src := mustCreateTestDirectory(t, d, "src")
+	mustCreateTestFile(t, filepath.Join(src, "file1"))
+	dst := filepath.Join(d, "dst")
+
+	if err := All(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verify(src, dst, OSFS{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Truncate(filepath.Join(dst, "file1"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verify(src, dst, OSFS{}); err == nil {
+		t.Error("expected error when sizes differ but no error was returned")
+	}
+}
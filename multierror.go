@@ -0,0 +1,93 @@
+package copy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ContinueOnError keeps copying the rest of the tree when an entry fails
+// instead of returning at the first failure. Every failure is collected
+// and returned together as a *MultiError once the copy is finished.
+func ContinueOnError() Option {
+	return func(o *options) {
+		o.continueOnError = true
+	}
+}
+
+// MultiError is returned when a copy made with ContinueOnError had one or
+// more failures. errors.Is and errors.As match against every contained
+// error.
+type MultiError struct {
+	// Errors holds each failure in the order it occurred.
+	Errors []error
+	// Copied is the number of entries that were copied successfully. A
+	// directory only counts if everything in it was copied as well.
+	Copied int
+}
+
+func (m *MultiError) Error() string {
+	msgs := make([]string, 0, len(m.Errors))
+	for _, err := range m.Errors {
+		msgs = append(msgs, "\t* "+err.Error())
+	}
+
+	return fmt.Sprintf("%d errors occurred during copy (%d copied):\n%s", len(m.Errors), m.Copied, strings.Join(msgs, "\n"))
+}
+
+// Unwrap returns the contained errors.
+func (m *MultiError) Unwrap() []error {
+	return m.Errors
+}
+
+// Is reports whether any contained error matches target.
+func (m *MultiError) Is(target error) bool {
+	for _, err := range m.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first contained error that matches target.
+func (m *MultiError) As(target interface{}) bool {
+	for _, err := range m.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// multiError returns the errors recorded in continue-on-error mode as a
+// *MultiError, or nil if there were none.
+func (o *options) multiError() error {
+	if len(o.errs) == 0 {
+		return nil
+	}
+
+	return &MultiError{Errors: o.errs, Copied: o.copied}
+}
+
+// countCopied counts an entry as copied unless a failure was recorded
+// below it, which is the case if there are more than errs errors.
+func (o *options) countCopied(errs int) {
+	if len(o.errs) == errs {
+		o.copied++
+	}
+}
+
+// fail records err in continue-on-error mode and reports whether the
+// caller should carry on with the next entry.
+func (o *options) fail(err error) bool {
+	if !o.continueOnError || o.aborted {
+		return false
+	}
+
+	o.errs = append(o.errs, err)
+
+	return true
+}
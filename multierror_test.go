@@ -0,0 +1,76 @@
+package copy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mustBlockFile makes a later copy of a file to path fail by putting a
+// non-empty directory in its place.
+func mustBlockFile(t *testing.T, path string) {
+	t.Helper()
+
+	mustCreateTestFile(t, filepath.Join(mustMkdirAll(t, path), "blocker"))
+}
+
+func TestContinueOnError(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "continue")
+	src := mustCreateTestDirectory(t, d, "src")
+	dst := filepath.Join(d, "dst")
+
+	for _, name := range []string{"bad1", "good", "bad2"} {
+		mustCreateTestFile(t, filepath.Join(src, name))
+	}
+
+	mustBlockFile(t, filepath.Join(dst, "bad1"))
+	mustBlockFile(t, filepath.Join(dst, "bad2"))
+
+	if err := All(src, dst); err == nil {
+		t.Fatal("expected error when copy failed but no error was returned")
+	}
+
+	mustNotExist(t, filepath.Join(dst, "good"))
+
+	err := All(src, dst, ContinueOnError())
+
+	var me *MultiError
+	if !errors.As(err, &me) {
+		t.Fatalf("expected *MultiError but got %v", err)
+	}
+
+	if len(me.Errors) != 2 {
+		t.Errorf("expected 2 errors but got %d", len(me.Errors))
+	}
+
+	// the top-level directory does not count since some of it failed
+	if me.Copied != 1 {
+		t.Errorf("expected 1 entry copied but got %d", me.Copied)
+	}
+
+	var pe *os.PathError
+	if !errors.As(err, &pe) {
+		t.Errorf("expected *os.PathError in %v", err)
+	}
+
+	if !strings.Contains(err.Error(), filepath.Join(src, "bad2")) {
+		t.Errorf("expected error for %s in %v", filepath.Join(src, "bad2"), err)
+	}
+
+	mustBeSameFile(t, filepath.Join(src, "good"), filepath.Join(dst, "good"))
+}
+
+func TestMultiErrorIs(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	me := &MultiError{Errors: []error{
+		&Error{Op: "Create", Src: "a", Dst: "b", Err: errors.New("other")},
+		&Error{Op: "Create", Src: "c", Dst: "d", Err: fmt.Errorf("wrapped: %w", sentinel)},
+	}}
+
+	if !errors.Is(me, sentinel) {
+		t.Error("expected MultiError to match contained sentinel")
+	}
+}
@@ -0,0 +1,156 @@
+package copy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrCopyIntoSelf is returned when a directory would be copied into
+// itself or into one of its own descendants.
+var ErrCopyIntoSelf = errors.New("cannot copy a directory into itself")
+
+// AllowNested allows a directory to be copied to a destination nested
+// inside it, such as All("a", "a/b"). The source is copied as it was
+// before the copy started: the destination and any directories created
+// above it are left out of it, so the copy does not recurse into itself.
+// Without it, such a copy fails with ErrCopyIntoSelf. Copying a directory
+// into one of its own descendants, such as All("a/b", "a"), always fails.
+func AllowNested() Option {
+	return func(o *options) {
+		o.allowNested = true
+	}
+}
+
+// checkNested returns an ErrCopyIntoSelf error if the directory src, which
+// is described by info, and dst are nested inside each other, either by
+// resolved path or by device and inode. Only copies from and to the local
+// disk are checked.
+func (o *options) checkNested(src, dst string, info os.FileInfo) error {
+	if o.fsys != nil || !onDisk(o.dest) || !info.IsDir() {
+		return nil
+	}
+
+	rsrc, err := resolve(src)
+	if err != nil {
+		return nil
+	}
+
+	rdst, err := resolve(dst)
+	if err != nil {
+		return nil
+	}
+
+	if within(rsrc, rdst) || below(rdst, info) {
+		if o.allowNested {
+			o.leaveOut(rdst)
+			o.debug("destination is nested in the source, leaving it out", src, dst)
+			return nil
+		}
+
+		return &Error{Op: "nested", Src: src, Dst: dst, Err: ErrCopyIntoSelf}
+	}
+
+	if within(rdst, rsrc) {
+		return &Error{Op: "nested", Src: src, Dst: dst, Err: ErrCopyIntoSelf}
+	}
+
+	if dstInfo, err := os.Stat(rdst); err == nil && below(rsrc, dstInfo) {
+		return &Error{Op: "nested", Src: src, Dst: dst, Err: ErrCopyIntoSelf}
+	}
+
+	return nil
+}
+
+// leaveOut records that the copy will create dst and any missing
+// directories above it, which AllowNested leaves out of the source.
+func (o *options) leaveOut(dst string) {
+	o.nested = append(o.nested, dst)
+
+	for dir := filepath.Dir(dst); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(dir); err == nil {
+			break
+		}
+
+		o.nested = append(o.nested, dir)
+	}
+}
+
+// isNestedDst reports whether the source entry described by info was
+// created by a copy nested in its source, which AllowNested leaves out.
+func (o *options) isNestedDst(info os.FileInfo) bool {
+	if len(o.nested) == 0 {
+		return false
+	}
+
+	if o.nestedInfos == nil {
+		o.nestedInfos = make(map[string]os.FileInfo, len(o.nested))
+	}
+
+	for _, path := range o.nested {
+		fi, ok := o.nestedInfos[path]
+		if !ok {
+			var err error
+			if fi, err = os.Lstat(path); err != nil {
+				continue
+			}
+
+			o.nestedInfos[path] = fi
+		}
+
+		if os.SameFile(fi, info) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolve returns the absolute path with symbolic links resolved, keeping
+// any trailing elements that do not exist yet.
+func resolve(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	var rest []string
+	for {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, rest...)...), nil
+		}
+
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
+// within reports whether path is strictly inside the directory dir.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// below reports whether a directory above path is the directory described
+// by info, which catches nesting through bind mounts and similar aliases.
+func below(path string, info os.FileInfo) bool {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if fi, err := os.Stat(dir); err == nil && os.SameFile(fi, info) {
+			return true
+		}
+
+		if filepath.Dir(dir) == dir {
+			return false
+		}
+	}
+}
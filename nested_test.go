@@ -0,0 +1,135 @@
+package copy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyIntoSelf(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "nested")
+	a := mustMkdirAll(t, filepath.Join(d, "a"))
+	mustCreateTestFile(t, filepath.Join(mustMkdirAll(t, filepath.Join(a, "sub")), "file"))
+
+	alias := filepath.Join(d, "alias")
+	if err := os.Symlink(a, alias); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		src, dst string
+	}{
+		"destination in source":    {a, filepath.Join(a, "b")},
+		"deeply nested":            {a, filepath.Join(a, "sub", "x", "y")},
+		"source in destination":    {filepath.Join(a, "sub"), a},
+		"destination through link": {alias + string(filepath.Separator), filepath.Join(a, "b")},
+		"source through link":      {a, filepath.Join(alias, "b")},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := All(tc.src, tc.dst)
+
+			var e *Error
+			if !errors.As(err, &e) || !errors.Is(err, ErrCopyIntoSelf) {
+				t.Fatalf("expected ErrCopyIntoSelf but got %v", err)
+			}
+		})
+	}
+
+	mustNotExist(t, filepath.Join(a, "b"))
+	mustNotExist(t, filepath.Join(a, "sub", "x"))
+}
+
+func TestCopyBesideSelf(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "nested")
+	a := mustMkdirAll(t, filepath.Join(d, "a"))
+	mustCreateTestFile(t, filepath.Join(a, "file"))
+
+	// a sibling sharing a name prefix is not nested
+	if err := All(a, filepath.Join(d, "ab")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAllowNested(t *testing.T) {
+	testCases := map[string]struct {
+		dst      string
+		existing bool
+	}{
+		"child":             {dst: "b"},
+		"existing child":    {dst: "b", existing: true},
+		"two levels down":   {dst: filepath.Join("x", "y")},
+		"inside source":     {dst: filepath.Join("sub", "x", "y")},
+		"existing grandkid": {dst: filepath.Join("x", "y"), existing: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			d := mustCreateTestDirectory(t, "", "nested")
+			a := mustMkdirAll(t, filepath.Join(d, "a"))
+			mustCreateTestFile(t, filepath.Join(mustMkdirAll(t, filepath.Join(a, "sub")), "file"))
+
+			dst := filepath.Join(a, tc.dst)
+			if tc.existing {
+				mustMkdirAll(t, dst)
+			}
+
+			if err := All(a, dst, AllowNested()); err != nil {
+				t.Fatal(err)
+			}
+
+			mustExist(t, filepath.Join(dst, "sub", "file"))
+
+			// nothing the copy created is copied into it
+			if !tc.existing {
+				for rel := filepath.Dir(tc.dst); rel != "."; rel = filepath.Dir(rel) {
+					if rel != "sub" {
+						mustNotExist(t, filepath.Join(dst, rel))
+					}
+				}
+			}
+			mustNotExist(t, filepath.Join(dst, tc.dst))
+		})
+	}
+}
+
+func TestCopyIntoSelfPointerDestination(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "nested")
+	a := mustMkdirAll(t, filepath.Join(d, "a"))
+	mustCreateTestFile(t, filepath.Join(a, "file"))
+
+	if err := All(a, filepath.Join(a, "b"), Destination(&OSFS{})); !errors.Is(err, ErrCopyIntoSelf) {
+		t.Fatalf("expected ErrCopyIntoSelf but got %v", err)
+	}
+}
+
+func TestAllowNestedSourceInDestination(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "nested")
+	a := mustMkdirAll(t, filepath.Join(d, "a"))
+	mustCreateTestFile(t, filepath.Join(mustMkdirAll(t, filepath.Join(a, "sub")), "file"))
+
+	if err := All(filepath.Join(a, "sub"), a, AllowNested()); !errors.Is(err, ErrCopyIntoSelf) {
+		t.Fatalf("expected ErrCopyIntoSelf but got %v", err)
+	}
+}
+
+func TestAllowNestedResume(t *testing.T) {
+	for _, rel := range []string{"b", filepath.Join("x", "y")} {
+		t.Run(rel, func(t *testing.T) {
+			d := mustCreateTestDirectory(t, "", "nested")
+			a := mustMkdirAll(t, filepath.Join(d, "a"))
+			mustCreateTestFile(t, filepath.Join(a, "file"))
+
+			dst := filepath.Join(a, rel)
+			if err := All(a, dst, AllowNested(), Resume()); err != nil {
+				t.Fatal(err)
+			}
+
+			mustExist(t, filepath.Join(dst, "file"))
+			mustNotExist(t, filepath.Join(dst, filepath.Base(journalPath(dst))))
+			mustNotExist(t, filepath.Join(dst, "x"))
+		})
+	}
+}
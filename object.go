@@ -0,0 +1,59 @@
+package copy
+
+import (
+	"os"
+)
+
+// Kind is the type of a file system object as classified by the copy
+// functions.
+type Kind int
+
+const (
+	// KindFile is a regular file.
+	KindFile Kind = iota
+	// KindDirectory is a directory.
+	KindDirectory
+	// KindSymlink is a symbolic link.
+	KindSymlink
+	// KindFIFO is a named pipe.
+	KindFIFO
+	// KindDevice is a character or block device.
+	KindDevice
+	// KindSocket is a unix socket.
+	KindSocket
+)
+
+var kindNames = [...]string{
+	KindFile:      "file",
+	KindDirectory: "directory",
+	KindSymlink:   "symlink",
+	KindFIFO:      "fifo",
+	KindDevice:    "device",
+	KindSocket:    "socket",
+}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "unknown"
+	}
+
+	return kindNames[k]
+}
+
+// Object is a file, directory, symlink or special file as seen by the copy
+// functions. Its Info describes the object itself and never the target of
+// a symlink.
+type Object interface {
+	// Kind returns how the object is copied.
+	Kind() Kind
+	// Path returns the path the object was read from.
+	Path() string
+	// Info returns the Lstat result for the object.
+	Info() os.FileInfo
+}
+
+// Stat returns the Object at path. Objects of a type the copy functions do
+// not support fail with ErrUnsupportedType.
+func Stat(path string) (Object, error) {
+	return newObject(path)
+}
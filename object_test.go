@@ -0,0 +1,56 @@
+package copy
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestStat(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "stat")
+	src := mustCreateTestTree(t, d)
+
+	testCases := []struct {
+		path string
+		kind Kind
+	}{
+		{src, KindDirectory},
+		{filepath.Join(src, "file2"), KindFile},
+		{filepath.Join(src, "link1"), KindSymlink},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.kind.String(), func(t *testing.T) {
+			obj, err := Stat(tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if obj.Kind() != tc.kind {
+				t.Errorf("expected kind %s but got %s", tc.kind, obj.Kind())
+			}
+			if obj.Path() != tc.path {
+				t.Errorf("expected path %s but got %s", tc.path, obj.Path())
+			}
+			if obj.Info().Name() != filepath.Base(tc.path) {
+				t.Errorf("expected info for %s but got %s", filepath.Base(tc.path), obj.Info().Name())
+			}
+		})
+	}
+}
+
+func TestStatError(t *testing.T) {
+	var e *Error
+	if _, err := Stat("/does/not/exist"); !errors.As(err, &e) || e.Op != "Lstat" {
+		t.Errorf("expected Lstat error but got %v", err)
+	}
+}
+
+func TestKindString(t *testing.T) {
+	if KindFIFO.String() != "fifo" {
+		t.Errorf("expected 'fifo' but got '%s'", KindFIFO)
+	}
+
+	if Kind(100).String() != "unknown" {
+		t.Errorf("expected 'unknown' but got '%s'", Kind(100))
+	}
+}
@@ -0,0 +1,120 @@
+package copy
+
+import (
+	"compress/flate"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Option configures optional behavior of a copy operation.
+type Option func(*options)
+
+// options holds the configuration for a single copy operation. It is
+// passed down through each copyObject as the tree is copied.
+type options struct {
+	linkOrCopy    bool
+	preserveTimes bool
+
+	// fsys is the source file system, nil for the local disk
+	fsys fs.FS
+	// dest is the destination file system
+	dest DestFS
+
+	filter func(path string, info os.FileInfo) bool
+
+	fifos   SpecialPolicy
+	devices SpecialPolicy
+	sockets SpecialPolicy
+
+	mirror       bool
+	mirrorLimit  int
+	mirrorDryRun func(path string, info os.FileInfo)
+	deleted      int
+
+	continueOnError bool
+	errs            []error
+	copied          int
+
+	compression int
+
+	reproducible bool
+	rootOwner    bool
+	// modTime replaces the modification time of copies and archive entries
+	// when set
+	modTime  time.Time
+	modeMask os.FileMode
+
+	handlers *Registry
+
+	beforeCopy func(src, dst string, info os.FileInfo) error
+	afterCopy  func(src, dst string, info os.FileInfo, err error) error
+	// aborted is set when a hook aborts the copy, which stops it even in
+	// continue-on-error mode
+	aborted bool
+
+	events chan<- Event
+	logger Logger
+	result *Result
+
+	limiter *Limiter
+	sync    SyncMode
+
+	resume  bool
+	journal *journal
+
+	transforms    []transform
+	transformMode func(path string, mode os.FileMode) os.FileMode
+
+	scaffold       bool
+	scaffoldData   interface{}
+	leftDelim      string
+	rightDelim     string
+	templateSuffix string
+
+	mapPath func(rel string) (string, bool)
+	srcRoot string
+	dstRoot string
+	// mapped records the source of each mapped destination and planned
+	// every destination, and the directories above it, that is kept from
+	// Mirror
+	mapped  map[string]mapping
+	planned map[string]bool
+
+	allowNested bool
+	// nested holds the paths a copy nested in its source creates and
+	// nestedInfos describes each of them once it exists
+	nested      []string
+	nestedInfos map[string]os.FileInfo
+}
+
+func newOptions(linkOrCopy bool, opts []Option) *options {
+	o := &options{linkOrCopy: linkOrCopy, dest: OSFS{}, compression: flate.DefaultCompression}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// Filter sets a function that decides whether the entry at the source path
+// is copied. Entries for which fn returns false are skipped, along with
+// everything below them. The top-level source is always copied.
+func Filter(fn func(path string, info os.FileInfo) bool) Option {
+	return func(o *options) {
+		o.filter = fn
+	}
+}
+
+// PreserveTimes sets the modification time of each copied file and
+// directory to that of its source. Symlink times are not preserved.
+func PreserveTimes() Option {
+	return func(o *options) {
+		o.preserveTimes = true
+	}
+}
+
+// included reports whether the entry at path passes the configured filter.
+func (o *options) included(path string, info os.FileInfo) bool {
+	return o.filter == nil || o.filter(path, info)
+}
@@ -0,0 +1,133 @@
+package copy
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reproducibleMask is the mode mask set by Reproducible.
+const reproducibleMask = 0022
+
+// unreproducibleXattrs are prefixes of extended attributes that depend on
+// the machine or on how a file was obtained rather than on its content.
+var unreproducibleXattrs = []string{
+	"security.selinux",
+	"security.ima",
+	"security.evm",
+	"trusted.",
+	"user.xdg.",
+}
+
+// Reproducible makes copies and archives depend only on the names, content
+// and permissions of the source tree so they are identical across machines.
+// It sets:
+//
+//   - ModTime to the time in the SOURCE_DATE_EPOCH environment variable, or
+//     the Unix epoch if it is unset or not a number of seconds
+//   - RootOwner, so archive entries are owned by root; copies are always
+//     owned by the user making them
+//   - ModeMask(0022), so nothing is group or other writable
+//
+// and leaves machine-specific extended attributes such as SELinux labels
+// out of tar streams. Entries are always walked in lexical order. Options
+// after Reproducible override the values it sets.
+func Reproducible() Option {
+	return func(o *options) {
+		o.reproducible = true
+		o.rootOwner = true
+		o.modTime = sourceDateEpoch()
+		o.modeMask = reproducibleMask
+	}
+}
+
+// RootOwner records every archive entry as owned by user and group 0
+// instead of the owner of the source. It applies to ToTar and ToCpio.
+func RootOwner() Option {
+	return func(o *options) {
+		o.rootOwner = true
+	}
+}
+
+// ModTime sets the modification time of every copied entry, including
+// symlinks on Linux, and every archive entry to t instead of the time of
+// the source. It takes precedence over PreserveTimes.
+func ModTime(t time.Time) Option {
+	return func(o *options) {
+		o.modTime = t
+	}
+}
+
+// ModeMask clears the permission bits in mask from the mode of every copied
+// entry and archive entry, like a umask. Entries hard linked by LinkOrCopy
+// share their mode with the source and are left unchanged.
+func ModeMask(mask os.FileMode) Option {
+	return func(o *options) {
+		o.modeMask = mask & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	}
+}
+
+// sourceDateEpoch returns the time in the SOURCE_DATE_EPOCH environment
+// variable, or the Unix epoch if it is unset or invalid.
+func sourceDateEpoch() time.Time {
+	sec, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64)
+	if err != nil || sec < 0 {
+		sec = 0
+	}
+
+	return time.Unix(sec, 0).UTC()
+}
+
+// mode returns the mode a copy or archive entry of a source with mode m
+// gets.
+func (o *options) mode(m os.FileMode) os.FileMode {
+	return m &^ o.modeMask
+}
+
+// modTimeOf returns the modification time recorded for the source fi.
+func (o *options) modTimeOf(fi os.FileInfo) time.Time {
+	if !o.modTime.IsZero() {
+		return o.modTime
+	}
+
+	return fi.ModTime()
+}
+
+// setTimes sets the times of the copy at dst of the source fi when times
+// are preserved or fixed.
+func (o *options) setTimes(dst string, fi os.FileInfo) error {
+	switch {
+	case !o.modTime.IsZero():
+		return o.dest.Chtimes(dst, o.modTime, o.modTime)
+	case o.preserveTimes:
+		return preserveTimes(o.dest, dst, fi)
+	}
+
+	return nil
+}
+
+// setLinkTimes sets the times of the symlink at dst when they are fixed.
+// Symlink times are never preserved.
+func (o *options) setLinkTimes(dst string) error {
+	if o.modTime.IsZero() {
+		return nil
+	}
+
+	return lchtimes(o.dest, dst, o.modTime, o.modTime)
+}
+
+// keepXattr reports whether the extended attribute name is recorded.
+func (o *options) keepXattr(name string) bool {
+	if !o.reproducible {
+		return true
+	}
+
+	for _, prefix := range unreproducibleXattrs {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,140 @@
+package copy
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func mustSetSourceDateEpoch(t *testing.T, value string) {
+	t.Helper()
+
+	if err := os.Setenv("SOURCE_DATE_EPOCH", value); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.Unsetenv("SOURCE_DATE_EPOCH") })
+}
+
+func TestReproducibleCopy(t *testing.T) {
+	mustSetSourceDateEpoch(t, "1500000000")
+	epoch := time.Unix(1500000000, 0)
+
+	d := mustCreateTestDirectory(t, "", "reproducible")
+	src := mustCreateTestTree(t, d)
+
+	if err := os.Chmod(filepath.Join(src, "file2"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(d, "dst")
+	if err := All(src, dst, Reproducible()); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(filepath.Join(dst, "file2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode() != 0644 {
+		t.Errorf("expected mode 0644 but got %v", fi.Mode())
+	}
+
+	paths := []string{"file2", "sub", "sub/file1"}
+	if runtime.GOOS == "linux" {
+		paths = append(paths, "link1")
+	}
+
+	for _, p := range paths {
+		fi, err := os.Lstat(filepath.Join(dst, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fi.ModTime().Equal(epoch) {
+			t.Errorf("expected %s to have mtime %v but got %v", p, epoch, fi.ModTime())
+		}
+	}
+}
+
+func TestModTimeOverridesPreserveTimes(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "reproducible")
+	src := mustCreateTestFile(t, filepath.Join(d, "src")).Name()
+	dst := filepath.Join(d, "dst")
+	mtime := time.Unix(1400000000, 0)
+
+	if err := All(src, dst, PreserveTimes(), ModTime(mtime)); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.ModTime().Equal(mtime) {
+		t.Errorf("expected mtime %v but got %v", mtime, fi.ModTime())
+	}
+}
+
+func TestReproducibleArchives(t *testing.T) {
+	mustSetSourceDateEpoch(t, "1500000000")
+
+	d := mustCreateTestDirectory(t, "", "reproducible")
+	first := mustCreateTestTree(t, mustCreateTestDirectory(t, d, "first"))
+	second := mustCreateTestTree(t, mustCreateTestDirectory(t, d, "second"))
+
+	// the trees differ only in metadata that is normalized
+	old := time.Unix(1000000000, 0)
+	if err := os.Chtimes(filepath.Join(second, "file2"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(second, "sub"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	writers := map[string]func(string, io.Writer, ...Option) error{
+		"tar":  ToTar,
+		"zip":  ToZip,
+		"cpio": ToCpio,
+	}
+
+	for name, write := range writers {
+		t.Run(name, func(t *testing.T) {
+			var a, b bytes.Buffer
+			if err := write(first, &a, Reproducible()); err != nil {
+				t.Fatal(err)
+			}
+			if err := write(second, &b, Reproducible()); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(a.Bytes(), b.Bytes()) {
+				t.Error("expected identical archives")
+			}
+		})
+	}
+}
+
+func TestKeepXattr(t *testing.T) {
+	o := newOptions(false, []Option{Reproducible()})
+
+	testCases := map[string]bool{
+		"user.comment":         true,
+		"security.capability":  true,
+		"security.selinux":     false,
+		"user.xdg.origin.url":  false,
+		"trusted.overlay.meta": false,
+	}
+
+	for name, expected := range testCases {
+		if o.keepXattr(name) != expected {
+			t.Errorf("expected keepXattr(%s) to be %t", name, expected)
+		}
+	}
+
+	if !newOptions(false, nil).keepXattr("security.selinux") {
+		t.Error("expected all xattrs to be kept by default")
+	}
+}
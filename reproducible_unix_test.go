@@ -0,0 +1,51 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package copy
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func mustSetUmask(t *testing.T, mask int) {
+	t.Helper()
+
+	old := syscall.Umask(mask)
+	t.Cleanup(func() { syscall.Umask(old) })
+}
+
+func TestReproducibleIgnoresUmask(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "reproducible")
+	src := mustCreateTestTree(t, d)
+
+	for _, dir := range []string{src, filepath.Join(src, "sub")} {
+		if err := os.Chmod(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// an existing destination directory is normalised as well
+	dst := filepath.Join(d, "dst")
+	if err := os.MkdirAll(filepath.Join(dst, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	mustSetUmask(t, 077)
+
+	if err := All(src, dst, Reproducible()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{"", "sub"} {
+		fi, err := os.Stat(filepath.Join(dst, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != 0755 {
+			t.Errorf("expected %s to have mode 0755 but got %v", filepath.Join(dst, p), fi.Mode().Perm())
+		}
+	}
+}
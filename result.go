@@ -0,0 +1,143 @@
+package copy
+
+import (
+	"time"
+)
+
+// Result summarizes what a copy did.
+type Result struct {
+	// Dirs is the number of directories created or reused.
+	Dirs int
+	// Files is the number of regular files written, either as a hard link
+	// or by copying their content.
+	Files int
+	// Linked is the number of files hard linked to the source by
+	// LinkOrCopy.
+	Linked int
+	// Copied is the number of files whose content was copied.
+	Copied int
+	// Symlinks is the number of symlinks created.
+	Symlinks int
+	// Special is the number of named pipes, devices and sockets created.
+	Special int
+	// Skipped is the number of entries left out by the Filter, a
+	// BeforeCopy hook or a special file policy.
+	Skipped int
+	// Removed is the number of entries removed by Mirror.
+	Removed int
+	// Failed is the number of entries that failed to copy.
+	Failed int
+	// Bytes is the number of bytes of file content written.
+	Bytes int64
+	// Duration is the wall time of the whole copy.
+	Duration time.Duration
+	// Phases splits the time spent by kind of work.
+	Phases Phases
+}
+
+// Phases holds the time a copy spent in each kind of work. Phases do not
+// overlap but do not add up to the whole duration either, since the rest
+// of the work, such as creating entries, is not attributed to a phase.
+type Phases struct {
+	// Scan is the time spent reading the source tree: Lstat and ReadDir.
+	Scan time.Duration
+	// Data is the time spent copying file content.
+	Data time.Duration
+	// Metadata is the time spent setting modes and times.
+	Metadata time.Duration
+	// Mirror is the time spent removing entries missing from the source.
+	Mirror time.Duration
+}
+
+// AllResult is All that also returns a summary of the copy. The Result is
+// returned even when the copy fails and describes the work done until it
+// stopped.
+func AllResult(src, dst string, opts ...Option) (*Result, error) {
+	return copyAllResult(src, dst, newOptions(false, opts))
+}
+
+// LinkOrCopyResult is LinkOrCopy that also returns a summary of the copy,
+// including how many files were linked rather than copied. The Result is
+// returned even when the copy fails.
+func LinkOrCopyResult(src, dst string, opts ...Option) (*Result, error) {
+	return copyAllResult(src, dst, newOptions(true, opts))
+}
+
+// copyAllResult runs copyAll and records its Result.
+func copyAllResult(src, dst string, o *options) (*Result, error) {
+	o.result = &Result{}
+	start := time.Now()
+
+	err := copyAll(src, dst, o)
+	o.result.Duration = time.Since(start)
+
+	return o.result, err
+}
+
+// record adds e to the result, if one is being recorded.
+func (o *options) record(e Event) {
+	r := o.result
+	if r == nil {
+		return
+	}
+
+	switch e.Type {
+	case EventDirCreated:
+		r.Dirs++
+	case EventFileCopied:
+		r.Files++
+		r.Copied++
+		r.Bytes += e.Bytes
+	case EventFileLinked:
+		r.Files++
+		r.Linked++
+	case EventSymlinkCreated:
+		r.Symlinks++
+	case EventSpecialCreated:
+		r.Special++
+	case EventSkipped:
+		r.Skipped++
+	case EventRemoved:
+		r.Removed++
+	case EventError:
+		r.Failed++
+	}
+}
+
+// phase is a kind of work timed in Phases.
+type phase int
+
+const (
+	phaseScan phase = iota
+	phaseData
+	phaseMetadata
+	phaseMirror
+)
+
+// timer starts timing work in phase p and returns a function that stops
+// it, if a result is being recorded.
+func (o *options) timer(p phase) func() {
+	if o.result == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+
+	return func() {
+		o.result.Phases.add(p, time.Since(start))
+	}
+}
+
+// add adds d to the time spent in phase ph.
+func (p *Phases) add(ph phase, d time.Duration) {
+	switch ph {
+	case phaseScan:
+		p.Scan += d
+	case phaseData:
+		p.Data += d
+	case phaseMetadata:
+		p.Metadata += d
+	case phaseMirror:
+		p.Mirror += d
+	}
+}
@@ -0,0 +1,76 @@
+package copy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAllResult(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "result")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+	mustCreateTestFile(t, filepath.Join(src, "excluded"))
+	mustMkdirAll(t, filepath.Join(dst, "extra"))
+
+	exclude := func(path string, _ os.FileInfo) bool {
+		return filepath.Base(path) != "excluded"
+	}
+
+	r, err := AllResult(src, dst, Filter(exclude), Mirror())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Result{Dirs: 2, Files: 2, Copied: 2, Symlinks: 1, Skipped: 1, Removed: 1, Bytes: 8}
+	got := *r
+	got.Duration, got.Phases = 0, Phases{}
+
+	if got != expected {
+		t.Errorf("expected %+v but got %+v", expected, got)
+	}
+
+	p := r.Phases
+	if r.Duration <= 0 || p.Scan+p.Data+p.Metadata+p.Mirror > r.Duration {
+		t.Errorf("expected phases %+v to be within the duration %v", p, r.Duration)
+	}
+}
+
+func TestLinkOrCopyResult(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "result")
+	src := mustCreateTestTree(t, d)
+
+	r, err := LinkOrCopyResult(src, filepath.Join(d, "dst"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Linked != 2 || r.Copied != 0 || r.Bytes != 0 {
+		t.Errorf("expected 2 linked files and no copies but got %+v", r)
+	}
+
+	r, err = LinkOrCopyResult(src, filepath.Join(d, "mem"), Destination(NewMemFS()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Linked != 0 || r.Copied != 2 {
+		t.Errorf("expected 2 copied files but got %+v", r)
+	}
+}
+
+func TestAllResultError(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "result")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+	mustBlockFile(t, filepath.Join(dst, "file2"))
+
+	r, err := AllResult(src, dst, ContinueOnError())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if r.Failed != 1 || r.Files != 1 {
+		t.Errorf("expected one failed and one copied file but got %+v", r)
+	}
+}
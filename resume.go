@@ -0,0 +1,360 @@
+package copy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// checkpointInterval is the number of bytes copied between journal
+	// checkpoints of a file.
+	checkpointInterval = 64 << 20
+	// verifyWindow is the number of bytes before the resume offset of a
+	// partial file that are compared with the source before continuing.
+	verifyWindow = 64 << 10
+)
+
+// errNoResume is returned when Resume is used with a destination that
+// cannot reopen files.
+var errNoResume = errors.New("destination does not support resuming")
+
+// Resume makes All, LinkOrCopy and FromFS resumable. Progress is recorded
+// in a journal file next to the destination, named after it with a leading
+// "." and a ".copy-journal" suffix. When a copy is rerun with the same
+// source and destination after being interrupted, files the journal
+// records as complete are skipped, partially written files are verified
+// and continued from their last checkpoint, and directory metadata is set
+// as usual. The journal is removed once a copy finishes without errors.
+//
+// A file is only resumed if its size and modification time still match
+// the journal. Files are flushed to stable storage before they are
+// recorded and the journal is flushed after each record, so a crash never
+// leaves the journal ahead of the data. The destination must have an
+// OpenAt method, as OSFS does. With AllowNested, a journal inside the
+// source is left out of the copy like the destination.
+func Resume() Option {
+	return func(o *options) {
+		o.resume = true
+	}
+}
+
+// OpenAt opens the existing regular file name for writing at offset,
+// truncating anything after it. The returned *os.File can also be read.
+func (OSFS) OpenAt(name string, offset int64) (io.WriteCloser, error) {
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = f.Truncate(offset); err == nil {
+		_, err = f.Seek(offset, io.SeekStart)
+	}
+
+	if err != nil {
+		closeFile(f)
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// journalRecord is a single line of a journal.
+type journalRecord struct {
+	// Path is the slash-separated path of the file relative to the
+	// top-level destination.
+	Path string `json:"path"`
+	// Size and ModTime identify the version of the source that was copied.
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mtime"`
+	// Offset is the number of bytes known to be on stable storage.
+	Offset int64 `json:"offset"`
+	Done   bool  `json:"done,omitempty"`
+}
+
+// journal records the progress of a resumable copy to root.
+type journal struct {
+	path    string
+	root    string
+	f       *os.File
+	records map[string]journalRecord
+}
+
+// journalPath returns the path of the journal for a copy to dst.
+func journalPath(dst string) string {
+	// the journal must be outside of dst even if dst is "."
+	if abs, err := filepath.Abs(dst); err == nil {
+		dst = abs
+	}
+
+	return filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".copy-journal")
+}
+
+// openJournal loads the journal for a copy to dst, if there is one, and
+// opens it for appending.
+func openJournal(dst string) (*journal, error) {
+	j := &journal{path: journalPath(dst), root: dst, records: make(map[string]journalRecord)}
+
+	b, err := ioutil.ReadFile(j.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// the last line may be torn by a crash, so invalid lines are ignored
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		var r journalRecord
+		if json.Unmarshal(s.Bytes(), &r) == nil {
+			j.records[r.Path] = r
+		}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(j.path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	if j.f, err = os.OpenFile(j.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// rel returns the journal key of the destination path dst.
+func (j *journal) rel(dst string) string {
+	rel, err := filepath.Rel(j.root, dst)
+	if err != nil {
+		return filepath.ToSlash(dst)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// lookup returns the record for dst if it was made for the source info.
+func (j *journal) lookup(dst string, info os.FileInfo) (journalRecord, bool) {
+	r, ok := j.records[j.rel(dst)]
+
+	return r, ok && r.Size == info.Size() && r.ModTime == info.ModTime().UnixNano()
+}
+
+// record appends a record for dst copied from the source info and flushes
+// the journal to stable storage.
+func (j *journal) record(dst string, info os.FileInfo, offset int64, done bool) error {
+	b, err := json.Marshal(journalRecord{
+		Path:    j.rel(dst),
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Offset:  offset,
+		Done:    done,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err = j.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+
+	return j.f.Sync()
+}
+
+// checkpoint flushes w, the file at dst, and records that offset bytes of
+// it are on stable storage.
+func (j *journal) checkpoint(dst string, info os.FileInfo, w io.Writer, offset int64, done bool) error {
+	if s, ok := w.(interface{ Sync() error }); ok {
+		if err := s.Sync(); err != nil {
+			return err
+		}
+	}
+
+	return j.record(dst, info, offset, done)
+}
+
+// done records that the file at dst, still open as w, is complete.
+func (j *journal) done(dst string, info os.FileInfo, w io.Writer) error {
+	return j.checkpoint(dst, info, w, info.Size(), true)
+}
+
+// close closes the journal and removes it if the copy finished.
+func (j *journal) close(finished bool) error {
+	err := j.f.Close()
+	if err != nil || !finished {
+		return err
+	}
+
+	return os.Remove(j.path)
+}
+
+// checkpointWriter writes to w and records a journal checkpoint for the
+// file f at dst every checkpointInterval bytes.
+type checkpointWriter struct {
+	w    io.Writer
+	f    io.Writer
+	j    *journal
+	dst  string
+	info os.FileInfo
+	// offset is the number of bytes of the file written so far
+	offset int64
+	// unconfirmed is the number of bytes written since the last checkpoint
+	unconfirmed int64
+}
+
+func (c *checkpointWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.offset += int64(n)
+	c.unconfirmed += int64(n)
+
+	if err == nil && c.unconfirmed >= checkpointInterval {
+		err = c.j.checkpoint(c.dst, c.info, c.f, c.offset, false)
+		c.unconfirmed = 0
+	}
+
+	return n, err
+}
+
+// resume continues the copy of f to dst recorded in the journal and
+// reports whether it did. A file that cannot be resumed is copied again
+// from the start.
+func (f file) resume(dst string, o *options) (bool, error) {
+	r, ok := o.journal.lookup(dst, f.info)
+	if !ok {
+		return false, nil
+	}
+
+	dstInfo, err := o.dest.Lstat(dst)
+	if err != nil || !dstInfo.Mode().IsRegular() {
+		return false, nil
+	}
+
+	if r.Done {
+		if dstInfo.Size() != f.info.Size() {
+			return false, nil
+		}
+
+		o.debug("skipped file completed by an earlier run", f.path, dst)
+		o.emit(Event{Type: EventSkipped, Src: f.path, Dst: dst, Info: f.info})
+
+		return true, nil
+	}
+
+	if dstInfo.Size() < r.Offset {
+		return false, nil
+	}
+
+	opener, ok := o.dest.(interface {
+		OpenAt(string, int64) (io.WriteCloser, error)
+	})
+	if !ok {
+		return false, nil
+	}
+
+	df, err := opener.OpenAt(dst, r.Offset)
+	if err != nil {
+		o.debug("cannot reopen partial file, copying again", f.path, dst, "err", err)
+		return false, nil
+	}
+
+	defer closeFile(df)
+
+	sf, err := open(o.fsys, f.path)
+	if err != nil {
+		return true, &Error{Op: "Open", Src: f.path, Dst: dst, Err: err}
+	}
+
+	defer closeFile(sf)
+
+	if ok, err = verifyPrefix(sf, df, r.Offset); err != nil {
+		return true, &Error{Op: "verify", Src: f.path, Dst: dst, Err: err}
+	}
+
+	if !ok {
+		o.debug("partial file differs from source, copying again", f.path, dst)
+		return false, nil
+	}
+
+	o.debug("resuming partial file", f.path, dst, "offset", r.Offset)
+
+	if err = o.dest.Chmod(dst, o.mode(f.info.Mode())); err != nil {
+		return true, &Error{Op: "Chmod", Src: f.path, Dst: dst, Err: err}
+	}
+
+	return true, f.write(dst, df, sf, r.Offset, o)
+}
+
+// verifyPrefix compares the verifyWindow bytes before offset in sf and df
+// and leaves sf positioned at offset. It reports false if they differ or
+// df cannot be read back.
+func verifyPrefix(sf io.Reader, df io.Writer, offset int64) (bool, error) {
+	ra, ok := df.(io.ReaderAt)
+	if !ok {
+		return false, nil
+	}
+
+	start := offset - verifyWindow
+	if start < 0 {
+		start = 0
+	}
+
+	// skip to the start of the window
+	if s, ok := sf.(io.Seeker); ok {
+		if _, err := s.Seek(start, io.SeekStart); err != nil {
+			return false, err
+		}
+	} else if _, err := io.CopyN(ioutil.Discard, sf, start); err != nil {
+		return false, err
+	}
+
+	want := make([]byte, offset-start)
+	if _, err := io.ReadFull(sf, want); err != nil {
+		return false, err
+	}
+
+	got := make([]byte, len(want))
+	if _, err := ra.ReadAt(got, start); err != nil {
+		return false, nil
+	}
+
+	return bytes.Equal(want, got), nil
+}
+
+// startJournal opens the journal of a resumable copy to dst.
+func (o *options) startJournal(dst string) error {
+	if _, ok := o.dest.(interface {
+		OpenAt(string, int64) (io.WriteCloser, error)
+	}); !ok {
+		return &Error{Op: "Resume", Dst: dst, Err: errNoResume}
+	}
+
+	// the journal and any directories created for it are not copied
+	if o.allowNested {
+		o.leaveOut(journalPath(dst))
+	}
+
+	j, err := openJournal(dst)
+	if err != nil {
+		return &Error{Op: "journal", Dst: journalPath(dst), Err: err}
+	}
+
+	o.journal = j
+
+	return nil
+}
+
+// finishJournal closes the journal of a resumable copy and removes it if
+// the copy finished without errors.
+func (o *options) finishJournal(err error) error {
+	if o.journal == nil {
+		return err
+	}
+
+	if cerr := o.journal.close(err == nil); cerr != nil && err == nil {
+		return &Error{Op: "journal", Dst: o.journal.path, Err: cerr}
+	}
+
+	return err
+}
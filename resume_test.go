@@ -0,0 +1,181 @@
+package copy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func mustWriteJournal(t *testing.T, dst string, records ...journalRecord) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	for _, r := range records {
+		b, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buf.Write(append(b, '\n'))
+	}
+
+	// a torn final line is ignored
+	buf.WriteString(`{"path":`)
+
+	if err := ioutil.WriteFile(journalPath(dst), buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustRecordFor(t *testing.T, path, rel string, offset int64, done bool) journalRecord {
+	t.Helper()
+
+	fi := mustStat(t, path)
+
+	return journalRecord{Path: rel, Size: fi.Size(), ModTime: fi.ModTime().UnixNano(), Offset: offset, Done: done}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestResumePartial(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "resume")
+	src := mustCreateTestDirectory(t, d, "src")
+	dst := filepath.Join(d, "dst")
+
+	content := bytes.Repeat([]byte("0123456789"), 20000)
+	srcFile := filepath.Join(src, "big")
+	if err := ioutil.WriteFile(srcFile, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// an interrupted run wrote and recorded the first half
+	half := int64(len(content) / 2)
+	mustMkdirAll(t, dst)
+	if err := ioutil.WriteFile(filepath.Join(dst, "big"), content[:half+100], 0644); err != nil {
+		t.Fatal(err)
+	}
+	mustWriteJournal(t, dst, mustRecordFor(t, srcFile, "big", half, false))
+
+	r, err := AllResult(src, dst, Resume())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Bytes != int64(len(content))-half {
+		t.Errorf("expected %d bytes to be copied but got %d", int64(len(content))-half, r.Bytes)
+	}
+
+	if !bytes.Equal(mustReadFile(t, filepath.Join(dst, "big")), content) {
+		t.Error("expected resumed file to match the source")
+	}
+
+	mustNotExist(t, journalPath(dst))
+}
+
+func TestResumeVerifyMismatch(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "resume")
+	src := mustCreateTestDirectory(t, d, "src")
+	dst := filepath.Join(d, "dst")
+	srcFile := mustCreateTestFile(t, filepath.Join(src, "file")).Name()
+
+	mustMkdirAll(t, dst)
+	if err := ioutil.WriteFile(filepath.Join(dst, "file"), []byte("XX"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mustWriteJournal(t, dst, mustRecordFor(t, srcFile, "file", 2, false))
+
+	if err := All(src, dst, Resume()); err != nil {
+		t.Fatal(err)
+	}
+
+	if b := mustReadFile(t, filepath.Join(dst, "file")); string(b) != "test" {
+		t.Errorf("expected file to be copied again but got '%s'", b)
+	}
+}
+
+func TestResumeDone(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "resume")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+
+	// the journal, not the content, decides that file2 is complete
+	mustMkdirAll(t, dst)
+	if err := ioutil.WriteFile(filepath.Join(dst, "file2"), []byte("done"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mustWriteJournal(t, dst, mustRecordFor(t, filepath.Join(src, "file2"), "file2", 4, true))
+
+	r, err := AllResult(src, dst, Resume())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b := mustReadFile(t, filepath.Join(dst, "file2")); string(b) != "done" {
+		t.Errorf("expected completed file to be skipped but got '%s'", b)
+	}
+
+	if r.Skipped != 1 || r.Copied != 1 {
+		t.Errorf("expected one skipped and one copied file but got %+v", r)
+	}
+
+	mustBeSameFile(t, filepath.Join(src, "sub", "file1"), filepath.Join(dst, "sub", "file1"))
+}
+
+func TestResumeKeepsJournal(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "resume")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+	mustBlockFile(t, filepath.Join(dst, "sub", "file1"))
+
+	if err := All(src, dst, Resume(), ContinueOnError()); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	j, err := openJournal(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.close(false)
+
+	if r, ok := j.lookup(filepath.Join(dst, "file2"), mustStat(t, filepath.Join(src, "file2"))); !ok || !r.Done {
+		t.Errorf("expected file2 to be recorded as done but got %+v", r)
+	}
+
+	if _, ok := j.records["sub/file1"]; ok {
+		t.Error("expected the failed file not to be recorded")
+	}
+}
+
+func TestResumeUnsupported(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "resume")
+	src := mustCreateTestTree(t, d)
+
+	err := All(src, filepath.Join(d, "dst"), Resume(), Destination(NewMemFS()))
+	if !errors.Is(err, errNoResume) {
+		t.Errorf("expected errNoResume but got %v", err)
+	}
+}
+
+func mustStat(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fi
+}
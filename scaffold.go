@@ -0,0 +1,131 @@
+package copy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// sniffLen is the number of leading bytes searched for a NUL byte to
+// decide whether a file is binary and must not be rendered.
+const sniffLen = 8000
+
+// Scaffold renders the content and the name of every copied file and
+// directory below the top-level source as a text/template template with
+// data, so that a skeleton such as "{{.ServiceName}}/main.go" can be
+// stamped out in one copy. The top-level destination is used as given.
+//
+// Binary files, recognised by a NUL byte near their start, are copied
+// verbatim. Use TemplateSuffix to only render the content of some files
+// and TemplateDelims to change the action delimiters. A reference to a
+// missing map key is an error. Template errors name the source path and
+// line of the failing template.
+//
+// Rendered files are always copied, never hard linked by LinkOrCopy.
+func Scaffold(data interface{}) Option {
+	return func(o *options) {
+		o.scaffold = true
+		o.scaffoldData = data
+		o.transforms = append(o.transforms, transform{match: o.renders, fn: o.render})
+	}
+}
+
+// TemplateDelims sets the action delimiters used by Scaffold, which
+// default to "{{" and "}}".
+func TemplateDelims(left, right string) Option {
+	return func(o *options) {
+		o.leftDelim = left
+		o.rightDelim = right
+	}
+}
+
+// TemplateSuffix makes Scaffold only render the content of files whose
+// names end with suffix, such as ".tmpl", and strip the suffix from the
+// names of their copies. Other files are copied verbatim, although their
+// names are still rendered.
+func TemplateSuffix(suffix string) Option {
+	return func(o *options) {
+		o.templateSuffix = suffix
+	}
+}
+
+// renders reports whether the content of the file at path is rendered.
+func (o *options) renders(path string, info os.FileInfo) bool {
+	return strings.HasSuffix(path, o.templateSuffix)
+}
+
+// render is the transform that renders the content of the file at path.
+// Binary files are streamed through without being read into memory.
+func (o *options) render(path string, r io.Reader) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, sniffLen)
+
+	sniff, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if bytes.IndexByte(sniff, 0) >= 0 {
+		return br, nil
+	}
+
+	b, err := ioutil.ReadAll(br)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = o.execute(path, string(b), &buf); err != nil {
+		return nil, err
+	}
+
+	return &buf, nil
+}
+
+// renderName returns name, the name of the copy of the directory entry at
+// path described by info, rendered.
+func (o *options) renderName(path, name string, info os.FileInfo) (string, error) {
+	if !o.scaffold {
+		return name, nil
+	}
+
+	if s := o.templateSuffix; s != "" && info.Mode().IsRegular() && len(name) > len(s) && strings.HasSuffix(name, s) {
+		name = strings.TrimSuffix(name, s)
+	}
+
+	left := o.leftDelim
+	if left == "" {
+		left = "{{"
+	}
+
+	if !strings.Contains(name, left) {
+		return name, nil
+	}
+
+	var buf bytes.Buffer
+	if err := o.execute(path, name, &buf); err != nil {
+		return "", err
+	}
+
+	rendered := buf.String()
+	if rendered == "" || rendered == "." || rendered == ".." || strings.ContainsAny(rendered, "/"+string(filepath.Separator)) {
+		return "", fmt.Errorf("template: %s: rendered invalid name %q", path, rendered)
+	}
+
+	return rendered, nil
+}
+
+// execute renders text, the template read from path, to w.
+func (o *options) execute(path, text string, w io.Writer) error {
+	t, err := template.New(path).Delims(o.leftDelim, o.rightDelim).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, o.scaffoldData)
+}
@@ -0,0 +1,119 @@
+package copy
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type scaffoldData struct {
+	ServiceName string
+	Port        int
+}
+
+func mustWriteFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScaffold(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "scaffold")
+	src := filepath.Join(d, "src")
+	binary := "\x00{{.ServiceName}}" + strings.Repeat("{{", sniffLen)
+
+	mustWriteFile(t, filepath.Join(src, "{{.ServiceName}}", "main.go"), "package {{.ServiceName}} // :{{.Port}}")
+	mustWriteFile(t, filepath.Join(src, "logo.bin"), binary)
+
+	dst := filepath.Join(d, "dst")
+	if err := All(src, dst, Scaffold(scaffoldData{ServiceName: "billing", Port: 8080})); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(mustReadFile(t, filepath.Join(dst, "billing", "main.go"))); got != "package billing // :8080" {
+		t.Errorf("expected rendered content but got %q", got)
+	}
+	if got := string(mustReadFile(t, filepath.Join(dst, "logo.bin"))); got != binary {
+		t.Errorf("expected binary file to be copied verbatim but got %q", got)
+	}
+}
+
+func TestScaffoldDelimsAndSuffix(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "scaffold")
+	src := filepath.Join(d, "src")
+
+	mustWriteFile(t, filepath.Join(src, "[[.ServiceName]].yaml.tmpl"), "name: [[.ServiceName]]")
+	mustWriteFile(t, filepath.Join(src, "README.md"), "keep [[.ServiceName]] {{.Other}}")
+
+	dst := filepath.Join(d, "dst")
+	data := map[string]string{"ServiceName": "billing"}
+	if err := All(src, dst, Scaffold(data), TemplateDelims("[[", "]]"), TemplateSuffix(".tmpl"), Mirror()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(mustReadFile(t, filepath.Join(dst, "billing.yaml"))); got != "name: billing" {
+		t.Errorf("expected rendered content but got %q", got)
+	}
+	if got := string(mustReadFile(t, filepath.Join(dst, "README.md"))); got != "keep [[.ServiceName]] {{.Other}}" {
+		t.Errorf("expected file without suffix to be copied verbatim but got %q", got)
+	}
+}
+
+func TestScaffoldErrors(t *testing.T) {
+	testCases := map[string]struct {
+		name    string
+		content string
+		want    string
+	}{
+		"content": {"main.go", "package main\n\n{{.Missing}}\n", "main.go:3:"},
+		"syntax":  {"main.go", "package main\n{{if}}\n", "main.go:2:"},
+		"name":    {"{{.ServiceName", "", "{{.ServiceName:1:"},
+		"invalid": {"{{.Path}}", "", "invalid name"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			d := mustCreateTestDirectory(t, "", "scaffold")
+			src := filepath.Join(d, "src")
+			mustWriteFile(t, filepath.Join(src, tc.name), tc.content)
+
+			data := map[string]string{"ServiceName": "billing", "Path": "a/b"}
+			err := All(src, filepath.Join(d, "dst"), Scaffold(data))
+
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("expected *Error but got %v", err)
+			}
+			if e.Src != filepath.Join(src, tc.name) {
+				t.Errorf("expected error for %s but got %s", tc.name, e.Src)
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error to contain %q but got %q", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestScaffoldLinkOrCopyRenders(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "scaffold")
+	src := filepath.Join(d, "src")
+	mustWriteFile(t, filepath.Join(src, "file"), "{{.}}")
+
+	dst := filepath.Join(d, "dst")
+	if err := LinkOrCopy(src, dst, Scaffold("rendered")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := mustReadFile(t, filepath.Join(dst, "file")); !bytes.Equal(got, []byte("rendered")) {
+		t.Errorf("expected rendered copy but got %q", got)
+	}
+}
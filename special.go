@@ -0,0 +1,158 @@
+package copy
+
+import (
+	"fmt"
+	"os"
+)
+
+// SpecialPolicy controls how named pipes, device nodes and sockets are
+// handled when they are encountered during a copy.
+type SpecialPolicy int
+
+const (
+	// SpecialError fails the copy with ErrSpecialFile. This is the
+	// default policy for every special file type.
+	SpecialError SpecialPolicy = iota
+	// SpecialSkip leaves the entry out of the destination.
+	SpecialSkip
+	// SpecialCreate recreates the entry at the destination with the source
+	// mode. Device nodes are created with the source device number, which
+	// usually requires privilege. Sockets are recreated as an unbound
+	// placeholder node.
+	SpecialCreate
+)
+
+// FIFOPolicy sets how named pipes are copied.
+func FIFOPolicy(p SpecialPolicy) Option {
+	return func(o *options) {
+		o.fifos = p
+	}
+}
+
+// DevicePolicy sets how character and block devices are copied.
+func DevicePolicy(p SpecialPolicy) Option {
+	return func(o *options) {
+		o.devices = p
+	}
+}
+
+// SocketPolicy sets how unix sockets are copied.
+func SocketPolicy(p SpecialPolicy) Option {
+	return func(o *options) {
+		o.sockets = p
+	}
+}
+
+type fifo struct {
+	base
+}
+
+func newFIFO(path string, fi os.FileInfo) fifo {
+	return fifo{base{path, fi}}
+}
+
+// copyTo recreates the p.path named pipe at dst according to the FIFO policy
+func (p fifo) copyTo(dst string, o *options) error {
+	return copySpecial(p.base, dst, o.fifos, o)
+}
+
+func (p fifo) Kind() Kind {
+	return KindFIFO
+}
+
+func (p fifo) String() string {
+	return "fifo: " + p.path
+}
+
+type device struct {
+	base
+}
+
+func newDevice(path string, fi os.FileInfo) device {
+	return device{base{path, fi}}
+}
+
+// copyTo recreates the d.path device node at dst according to the device policy
+func (d device) copyTo(dst string, o *options) error {
+	return copySpecial(d.base, dst, o.devices, o)
+}
+
+func (d device) Kind() Kind {
+	return KindDevice
+}
+
+func (d device) String() string {
+	return "device: " + d.path
+}
+
+type socket struct {
+	base
+}
+
+func newSocket(path string, fi os.FileInfo) socket {
+	return socket{base{path, fi}}
+}
+
+// copyTo recreates the s.path socket at dst according to the socket policy
+func (s socket) copyTo(dst string, o *options) error {
+	return copySpecial(s.base, dst, o.sockets, o)
+}
+
+func (s socket) Kind() Kind {
+	return KindSocket
+}
+
+func (s socket) String() string {
+	return "socket: " + s.path
+}
+
+// specialPolicy returns the policy configured for obj if it is a special
+// file.
+func specialPolicy(obj copyObject, o *options) (SpecialPolicy, bool) {
+	switch obj.(type) {
+	case fifo:
+		return o.fifos, true
+	case device:
+		return o.devices, true
+	case socket:
+		return o.sockets, true
+	default:
+		return SpecialError, false
+	}
+}
+
+// copySpecial applies policy to copy the special file b to dst.
+func copySpecial(b base, dst string, policy SpecialPolicy, o *options) error {
+	switch policy {
+	case SpecialSkip:
+		o.debug("skipped special file", b.path, dst, "mode", b.info.Mode())
+		o.emit(Event{Type: EventSkipped, Src: b.path, Dst: dst, Info: b.info})
+		return nil
+	case SpecialCreate:
+	default:
+		return &Error{Op: "copyTo", Src: b.path, Dst: dst, Err: fmt.Errorf("%w %s", ErrSpecialFile, b.info.Mode())}
+	}
+
+	if err := o.dest.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return &Error{Op: "Remove", Src: b.path, Dst: dst, Err: err}
+	} else if err == nil {
+		o.debug("removed existing destination", b.path, dst)
+	}
+
+	if err := mknodDest(o.dest, dst, b.info); err != nil {
+		return &Error{Op: "Mknod", Src: b.path, Dst: dst, Err: err}
+	}
+
+	// mknod is subject to the umask, set the exact source permissions
+	if err := o.dest.Chmod(dst, o.mode(b.info.Mode())); err != nil {
+		return &Error{Op: "Chmod", Src: b.path, Dst: dst, Err: err}
+	}
+
+	if err := o.setTimes(dst, b.info); err != nil {
+		return &Error{Op: "Chtimes", Src: b.path, Dst: dst, Err: err}
+	}
+
+	o.emit(Event{Type: EventSpecialCreated, Src: b.path, Dst: dst, Info: b.info})
+
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package copy
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// mknod is not supported on this platform.
+func mknod(path string, fi os.FileInfo) error {
+	return fmt.Errorf("cannot create %s %s on %s", fi.Mode().String(), path, runtime.GOOS)
+}
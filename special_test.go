@@ -0,0 +1,147 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package copy
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func mustCreateTestFIFO(t *testing.T, path string) {
+	t.Helper()
+
+	if err := sysMknod(path, syscall.S_IFIFO|0640, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustCreateTestSocket(t *testing.T, path string) {
+	t.Helper()
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = l.Close() })
+}
+
+func TestNewObjectSpecial(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "special")
+	p := filepath.Join(d, "fifo")
+	mustCreateTestFIFO(t, p)
+	s := filepath.Join(d, "sock")
+	mustCreateTestSocket(t, s)
+
+	testCases := []struct {
+		name, filepath string
+		expected       reflect.Type
+	}{
+		{"fifo", p, reflect.TypeOf(fifo{})},
+		{"device", os.DevNull, reflect.TypeOf(device{})},
+		{"socket", s, reflect.TypeOf(socket{})},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := newObject(tc.filepath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := reflect.TypeOf(o)
+			if actual != tc.expected {
+				t.Errorf("expected %s but got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSpecialPolicy(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "special")
+	src := mustCreateTestDirectory(t, d, "src")
+	mustCreateTestFIFO(t, filepath.Join(src, "fifo"))
+
+	if err := All(src, filepath.Join(d, "default")); !errors.Is(err, ErrSpecialFile) || !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected ErrSpecialFile for fifo under default policy but got %v", err)
+	}
+
+	skipped := filepath.Join(d, "skip")
+	if err := All(src, skipped, FIFOPolicy(SpecialSkip)); err != nil {
+		t.Fatal(err)
+	}
+
+	mustNotExist(t, filepath.Join(skipped, "fifo"))
+
+	created := filepath.Join(d, "create")
+	if err := All(src, created, FIFOPolicy(SpecialCreate)); err != nil {
+		t.Fatal(err)
+	}
+
+	mustBeSameFile(t, filepath.Join(src, "fifo"), filepath.Join(created, "fifo"))
+}
+
+func TestSpecialPolicyPerType(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "special")
+	p := filepath.Join(d, "fifo")
+	mustCreateTestFIFO(t, p)
+	s := filepath.Join(d, "sock")
+	mustCreateTestSocket(t, s)
+
+	testCases := []struct {
+		name, path string
+		policy     func(SpecialPolicy) Option
+	}{
+		{"fifo", p, FIFOPolicy},
+		{"device", os.DevNull, DevicePolicy},
+		{"socket", s, SocketPolicy},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dst := filepath.Join(d, tc.name+"-copy")
+
+			if err := All(tc.path, dst); !errors.Is(err, ErrSpecialFile) {
+				t.Errorf("expected ErrSpecialFile under default policy but got %v", err)
+			}
+
+			if err := All(tc.path, dst, tc.policy(SpecialSkip)); err != nil {
+				t.Fatal(err)
+			}
+
+			mustNotExist(t, dst)
+		})
+	}
+}
+
+func TestSocketCreate(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "special")
+	s := filepath.Join(d, "sock")
+	mustCreateTestSocket(t, s)
+	dst := filepath.Join(d, "sockcopy")
+
+	if err := All(s, dst, SocketPolicy(SpecialCreate)); err != nil {
+		t.Fatal(err)
+	}
+
+	mustBeSameFile(t, s, dst)
+}
+
+func TestDeviceCreate(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("creating device nodes requires root")
+	}
+
+	dst := filepath.Join(mustCreateTestDirectory(t, "", "special"), "null")
+
+	if err := All(os.DevNull, dst, DevicePolicy(SpecialCreate)); err != nil {
+		t.Fatal(err)
+	}
+
+	mustBeSameFile(t, os.DevNull, dst)
+}
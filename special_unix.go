@@ -0,0 +1,57 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package copy
+
+import (
+	"archive/tar"
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// mknod creates a special file at path with the type, permissions and
+// device number of fi.
+func mknod(path string, fi os.FileInfo) error {
+	var mode uint32
+
+	switch {
+	case fi.Mode()&os.ModeNamedPipe != 0:
+		mode = syscall.S_IFIFO
+	case fi.Mode()&os.ModeSocket != 0:
+		mode = syscall.S_IFSOCK
+	case fi.Mode()&os.ModeCharDevice != 0:
+		mode = syscall.S_IFCHR
+	default:
+		mode = syscall.S_IFBLK
+	}
+
+	var dev int
+
+	switch sys := fi.Sys().(type) {
+	case *syscall.Stat_t:
+		dev = int(sys.Rdev)
+	case *tar.Header:
+		if mode == syscall.S_IFCHR || mode == syscall.S_IFBLK {
+			var err error
+			if dev, err = mkdev(sys.Devmajor, sys.Devminor); err != nil {
+				return err
+			}
+		}
+	}
+
+	return sysMknod(path, mode|uint32(fi.Mode().Perm()), dev)
+}
+
+// mkdev returns the device number for the major and minor numbers.
+func mkdev(major, minor int64) (int, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return int((major&0xfffff000)<<32 | (major&0xfff)<<8 | (minor&0xffffff00)<<12 | minor&0xff), nil
+	case "darwin":
+		return int(major<<24 | minor), nil
+	default:
+		return 0, fmt.Errorf("device numbers are not supported on %s", runtime.GOOS)
+	}
+}
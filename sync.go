@@ -0,0 +1,127 @@
+package copy
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SyncMode selects how a copy is flushed to stable storage.
+type SyncMode int
+
+const (
+	// SyncNone leaves flushing to the operating system. A power loss
+	// shortly after a copy returns can lose copied data. This is the
+	// default.
+	SyncNone SyncMode = iota
+	// SyncEach fsyncs each file after it is written and each directory
+	// after its entries are created, along with the parent of the
+	// top-level destination and any directories created above it, so
+	// everything copied is durable when the copy returns.
+	SyncEach
+	// SyncBatch flushes the whole destination file system once at the end
+	// of the copy with syncfs, which is much faster than SyncEach for many
+	// small files. It falls back to SyncEach on platforms other than Linux
+	// and on architectures without a known syncfs system call.
+	SyncBatch
+)
+
+// Durability sets how a copy made with All, LinkOrCopy or FromFS is flushed
+// to stable storage. Destinations without Fsync and Syncfs methods, such as
+// MemFS, are not flushed.
+func Durability(mode SyncMode) Option {
+	return func(o *options) {
+		o.sync = mode
+	}
+}
+
+// syncEach reports whether each entry is flushed as it is copied.
+func (o *options) syncEach() bool {
+	return o.sync == SyncEach || o.sync == SyncBatch && !syncfsSupported
+}
+
+// syncFile flushes the file at dst that is still open for writing as w.
+func (o *options) syncFile(dst string, w io.Writer) error {
+	if !o.syncEach() {
+		return nil
+	}
+
+	if s, ok := w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+
+	return nil
+}
+
+// syncDir flushes the directory at dst, if each entry is flushed.
+func (o *options) syncDir(dst string) error {
+	if !o.syncEach() {
+		return nil
+	}
+
+	return fsync(o.dest, dst)
+}
+
+// mkdirAll creates the directory name and any missing parents in the
+// destination. If each entry is flushed, the parents it creates and the
+// directory above them are flushed too, which leaves flushing the content
+// of name to the caller.
+func (o *options) mkdirAll(name string, perm os.FileMode) error {
+	var created []string
+
+	if o.syncEach() {
+		for dir := filepath.Dir(name); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			if _, err := o.dest.Lstat(dir); err == nil {
+				break
+			}
+
+			created = append(created, dir)
+		}
+	}
+
+	if err := mkdirAll(o.dest, name, perm); err != nil {
+		return err
+	}
+
+	if len(created) > 0 {
+		created = append(created, filepath.Dir(created[len(created)-1]))
+	}
+
+	for _, dir := range created {
+		if err := fsync(o.dest, dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncDone finishes a copy to dst by flushing the parent of dst so the
+// top-level entry itself is durable, or in batch mode the whole file
+// system.
+func (o *options) syncDone(dst string) error {
+	parent := filepath.Dir(dst)
+
+	switch {
+	case o.syncEach():
+		return wrapError("Fsync", "", parent, fsync(o.dest, parent))
+	case o.sync == SyncBatch:
+		s, ok := o.dest.(interface{ Syncfs(string) error })
+		if !ok {
+			return nil
+		}
+
+		return wrapError("Syncfs", "", parent, s.Syncfs(parent))
+	}
+
+	return nil
+}
+
+// fsync flushes name in d if d has an Fsync method.
+func fsync(d DestFS, name string) error {
+	if s, ok := d.(interface{ Fsync(string) error }); ok {
+		return s.Fsync(name)
+	}
+
+	return nil
+}
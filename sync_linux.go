@@ -0,0 +1,51 @@
+package copy
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// sysSyncfs holds the syncfs system call number of each architecture,
+// which the syscall package does not export.
+var sysSyncfs = map[string]uintptr{
+	"386":      344,
+	"amd64":    306,
+	"arm":      373,
+	"arm64":    267,
+	"loong64":  267,
+	"mips":     4342,
+	"mipsle":   4342,
+	"mips64":   5301,
+	"mips64le": 5301,
+	"ppc64":    348,
+	"ppc64le":  348,
+	"riscv64":  267,
+	"s390x":    338,
+}
+
+// syncfsSupported reports whether syncfs is available, which depends on
+// the architecture.
+var syncfsSupported = sysSyncfs[runtime.GOARCH] != 0
+
+// syncfs flushes the file system containing name. It is not supported on
+// an architecture without a known syncfs system call.
+func syncfs(name string) error {
+	trap, ok := sysSyncfs[runtime.GOARCH]
+	if !ok {
+		return &os.PathError{Op: "syncfs", Path: name, Err: errNotSupported}
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+
+	defer closeFile(f)
+
+	if _, _, errno := syscall.Syscall(trap, f.Fd(), 0, 0); errno != 0 {
+		return &os.PathError{Op: "syncfs", Path: name, Err: errno}
+	}
+
+	return nil
+}
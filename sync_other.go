@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package copy
+
+import "os"
+
+// syncfsSupported reports whether syncfs is available.
+const syncfsSupported = false
+
+// syncfs is not supported on this platform.
+func syncfs(name string) error {
+	return &os.PathError{Op: "syncfs", Path: name, Err: errNotSupported}
+}
@@ -0,0 +1,133 @@
+package copy
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// syncRecorder is an OSFS that records what is flushed.
+type syncRecorder struct {
+	OSFS
+	synced []string
+	syncfs []string
+}
+
+type syncRecorderFile struct {
+	*os.File
+	r *syncRecorder
+}
+
+func (f syncRecorderFile) Sync() error {
+	f.r.synced = append(f.r.synced, f.Name())
+	return f.File.Sync()
+}
+
+func (r *syncRecorder) Create(name string) (io.WriteCloser, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return syncRecorderFile{File: f, r: r}, nil
+}
+
+func (r *syncRecorder) Fsync(name string) error {
+	r.synced = append(r.synced, name)
+	return r.OSFS.Fsync(name)
+}
+
+func (r *syncRecorder) Syncfs(name string) error {
+	r.syncfs = append(r.syncfs, name)
+	return r.OSFS.Syncfs(name)
+}
+
+func TestDurabilitySyncEach(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "sync")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "dst")
+
+	r := &syncRecorder{}
+	if err := All(src, dst, Destination(r), Durability(SyncEach)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		d,
+		dst,
+		filepath.Join(dst, "file2"),
+		filepath.Join(dst, "sub"),
+		filepath.Join(dst, "sub", "file1"),
+	}
+
+	sort.Strings(r.synced)
+	if !reflect.DeepEqual(r.synced, expected) {
+		t.Errorf("expected %v to be synced but got %v", expected, r.synced)
+	}
+
+	if len(r.syncfs) != 0 {
+		t.Errorf("expected no syncfs but got %v", r.syncfs)
+	}
+}
+
+func TestDurabilityCreatedParents(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "sync")
+	src := mustCreateTestTree(t, d)
+	dst := filepath.Join(d, "x", "y", "dst")
+
+	r := &syncRecorder{}
+	if err := All(src, dst, Destination(r), Durability(SyncEach)); err != nil {
+		t.Fatal(err)
+	}
+
+	synced := make(map[string]bool)
+	for _, name := range r.synced {
+		synced[name] = true
+	}
+
+	// each new directory entry is flushed in the directory holding it
+	for _, dir := range []string{d, filepath.Join(d, "x"), filepath.Join(d, "x", "y")} {
+		if !synced[dir] {
+			t.Errorf("expected %s to be synced but got %v", dir, r.synced)
+		}
+	}
+}
+
+func TestDurabilitySyncBatch(t *testing.T) {
+	if !syncfsSupported {
+		t.Skip("syncfs is not supported on this platform")
+	}
+
+	d := mustCreateTestDirectory(t, "", "sync")
+	src := mustCreateTestTree(t, d)
+
+	r := &syncRecorder{}
+	if err := All(src, filepath.Join(d, "dst"), Destination(r), Durability(SyncBatch)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.synced) != 0 {
+		t.Errorf("expected nothing to be synced individually but got %v", r.synced)
+	}
+
+	if !reflect.DeepEqual(r.syncfs, []string{d}) {
+		t.Errorf("expected a single syncfs of %s but got %v", d, r.syncfs)
+	}
+}
+
+func TestDurabilityNone(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "sync")
+	src := mustCreateTestTree(t, d)
+
+	r := &syncRecorder{}
+	if err := All(src, filepath.Join(d, "dst"), Destination(r)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.synced) != 0 || len(r.syncfs) != 0 {
+		t.Errorf("expected nothing to be synced but got %v and %v", r.synced, r.syncfs)
+	}
+}
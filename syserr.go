@@ -0,0 +1,17 @@
+//go:build !plan9
+// +build !plan9
+
+package copy
+
+import "syscall"
+
+// Errors returned by helpers that emulate system calls. Plan 9 has no
+// errno values, so they are defined per platform.
+var (
+	// errNotSupported is returned when the platform lacks an operation.
+	errNotSupported error = syscall.ENOTSUP
+	// errCrossDevice is returned when a rename or link crosses devices.
+	errCrossDevice error = syscall.EXDEV
+	// errNotEmpty is returned when removing a directory that has entries.
+	errNotEmpty error = syscall.ENOTEMPTY
+)
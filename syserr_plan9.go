@@ -0,0 +1,14 @@
+package copy
+
+import "errors"
+
+// Errors returned by helpers that emulate system calls. Plan 9 has no
+// errno values, so they are defined per platform.
+var (
+	// errNotSupported is returned when the platform lacks an operation.
+	errNotSupported = errors.New("operation not supported")
+	// errCrossDevice is returned when a rename or link crosses devices.
+	errCrossDevice = errors.New("cross-device link")
+	// errNotEmpty is returned when removing a directory that has entries.
+	errNotEmpty = errors.New("directory not empty")
+)
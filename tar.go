@@ -0,0 +1,95 @@
+package copy
+
+import (
+	"archive/tar"
+	"io"
+	"time"
+)
+
+// ToTar writes src and everything below it to w as a PAX tar stream. Entry
+// names are relative to src, which itself is stored as "./" when it is a
+// directory or under its base name otherwise. Modes, modification times,
+// ownership and, on Linux, extended attributes are recorded. A file with
+// several hard links is stored once and its other names are stored as hard
+// link entries.
+//
+// ToTar honors the Filter, ContinueOnError, special file policy, RootOwner,
+// ModTime, ModeMask and Reproducible options. Named pipes and devices are
+// stored when their policy is SpecialCreate. Sockets cannot be stored in a
+// tar stream.
+func ToTar(src string, w io.Writer, opts ...Option) error {
+	return writeArchive(src, &tarWriter{tw: tar.NewWriter(w)}, newOptions(false, opts))
+}
+
+// tarWriter is an archiver that writes a tar stream.
+type tarWriter struct {
+	tw *tar.Writer
+}
+
+func (t *tarWriter) writeEntry(e *archiveEntry) error {
+	hdr, err := tar.FileInfoHeader(e.obj.Info(), e.target)
+	if err != nil {
+		return &Error{Op: "FileInfoHeader", Src: e.obj.Path(), Err: err}
+	}
+
+	hdr.Name = e.name
+	hdr.Format = tar.FormatPAX
+	hdr.Mode &^= int64(unixMode(e.o.modeMask) & 07777)
+
+	if !e.o.modTime.IsZero() {
+		hdr.ModTime = e.o.modTime
+		hdr.AccessTime = time.Time{}
+		hdr.ChangeTime = time.Time{}
+	}
+
+	if e.o.rootOwner {
+		hdr.Uid, hdr.Gid = 0, 0
+		hdr.Uname, hdr.Gname = "", ""
+	}
+
+	switch e.obj.(type) {
+	case directory:
+		hdr.Name += "/"
+	case file:
+		if e.linkname != "" {
+			hdr.Typeflag = tar.TypeLink
+			hdr.Linkname = e.linkname
+			hdr.Size = 0
+		}
+	}
+
+	// xattrs are only read from disk and never from symlinks, which would
+	// report the attributes of their target
+	if _, ok := e.obj.(link); !ok && e.o.fsys == nil {
+		attrs, err := xattrs(e.obj.Path())
+		if err != nil {
+			return &Error{Op: "Listxattr", Src: e.obj.Path(), Err: err}
+		}
+
+		for k, v := range attrs {
+			if !e.o.keepXattr(k) {
+				continue
+			}
+
+			if hdr.PAXRecords == nil {
+				hdr.PAXRecords = make(map[string]string)
+			}
+
+			hdr.PAXRecords["SCHILY.xattr."+k] = v
+		}
+	}
+
+	if err = t.tw.WriteHeader(hdr); err != nil {
+		return &Error{Op: "WriteHeader", Src: e.obj.Path(), Dst: hdr.Name, Err: err}
+	}
+
+	if hdr.Typeflag == tar.TypeReg {
+		return e.writeContent(t.tw)
+	}
+
+	return nil
+}
+
+func (t *tarWriter) close() error {
+	return t.tw.Close()
+}
@@ -0,0 +1,126 @@
+package copy
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func mustReadTar(t *testing.T, r io.Reader) map[string]*tar.Header {
+	t.Helper()
+
+	headers := make(map[string]*tar.Header)
+	tr := tar.NewReader(r)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return headers
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		headers[hdr.Name] = hdr
+	}
+}
+
+func TestToTar(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "totar")
+	src := mustCreateTestDirectory(t, d, "src")
+	sub := mustMkdirAll(t, filepath.Join(src, "sub"))
+	mustCreateTestFile(t, filepath.Join(sub, "file1"))
+	mustCreateTestFile(t, filepath.Join(src, "excluded"))
+	mustCreateTestLink(t, filepath.Join(src, "link1"), "sub/file1")
+
+	if err := os.Link(filepath.Join(sub, "file1"), filepath.Join(src, "hardlink")); err != nil {
+		t.Fatal(err)
+	}
+
+	exclude := func(path string, _ os.FileInfo) bool {
+		return filepath.Base(path) != "excluded"
+	}
+
+	var buf bytes.Buffer
+	if err := ToTar(src, &buf, Filter(exclude)); err != nil {
+		t.Fatal(err)
+	}
+
+	headers := mustReadTar(t, &buf)
+
+	testCases := []struct {
+		name     string
+		typeflag byte
+		linkname string
+	}{
+		{"./", tar.TypeDir, ""},
+		{"sub/", tar.TypeDir, ""},
+		{"hardlink", tar.TypeReg, ""},
+		{"sub/file1", tar.TypeLink, "hardlink"},
+		{"link1", tar.TypeSymlink, "sub/file1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hdr, ok := headers[tc.name]
+			if !ok {
+				t.Fatalf("expected entry %s in %v", tc.name, headers)
+			}
+			if hdr.Typeflag != tc.typeflag {
+				t.Errorf("expected type %c but got %c", tc.typeflag, hdr.Typeflag)
+			}
+			if hdr.Linkname != tc.linkname {
+				t.Errorf("expected link name '%s' but got '%s'", tc.linkname, hdr.Linkname)
+			}
+		})
+	}
+
+	if _, ok := headers["excluded"]; ok {
+		t.Error("expected filtered entry to be left out of the tar stream")
+	}
+
+	if len(headers) != len(testCases) {
+		t.Errorf("expected %d entries but got %d", len(testCases), len(headers))
+	}
+}
+
+func TestToTarFile(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "totar")
+	f := mustCreateTestFile(t, filepath.Join(d, "file1"))
+
+	var buf bytes.Buffer
+	if err := ToTar(f.Name(), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := tar.NewReader(&buf)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hdr.Name != "file1" {
+		t.Errorf("expected entry 'file1' but got '%s'", hdr.Name)
+	}
+
+	b, err := ioutil.ReadAll(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "test" {
+		t.Errorf("expected 'test' but got '%s'", b)
+	}
+}
+
+func TestToTarError(t *testing.T) {
+	if err := ToTar("none", ioutil.Discard); err == nil {
+		t.Error("expected error when file does not exist but no error was returned")
+	}
+}
@@ -0,0 +1,145 @@
+package copy
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Limiter limits the rate at which file content is written with a token
+// bucket. A single Limiter is shared by every file copy made with it, so
+// it bounds the total bandwidth of one or more operations. Its rate can be
+// changed at any time, including while a copy is running. A Limiter is
+// safe for concurrent use.
+type Limiter struct {
+	mu sync.Mutex
+	// rate is in bytes per second; zero or less is unlimited
+	rate  float64
+	burst int64
+	// tokens is the number of bytes that may be written now, negative when
+	// writers are waiting for the bucket to refill
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a Limiter that allows rate bytes per second on
+// average and bursts of up to burst bytes. A rate of zero or less does not
+// limit. A burst of zero or less allows bursts of one second at rate.
+func NewLimiter(rate, burst int64) *Limiter {
+	l := &Limiter{last: time.Now()}
+	l.SetRate(rate, burst)
+	l.tokens = float64(l.burst)
+
+	return l
+}
+
+// SetRate changes the rate and burst of l as described for NewLimiter.
+// Writes that are already waiting are not sped up or slowed down.
+func (l *Limiter) SetRate(rate, burst int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+
+	if burst <= 0 {
+		burst = rate
+	}
+
+	if burst <= 0 {
+		burst = 1
+	}
+
+	l.rate = float64(rate)
+	l.burst = burst
+
+	if l.tokens > float64(burst) {
+		l.tokens = float64(burst)
+	}
+}
+
+// refill adds the tokens accumulated since the last refill.
+func (l *Limiter) refill(now time.Time) {
+	if l.rate > 0 {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+
+	l.last = now
+}
+
+// wait blocks until n bytes may be written. n must not exceed the burst.
+func (l *Limiter) wait(n int) {
+	l.mu.Lock()
+
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return
+	}
+
+	l.refill(time.Now())
+	l.tokens -= float64(n)
+
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+
+	l.mu.Unlock()
+
+	time.Sleep(delay)
+}
+
+// maxInt is the largest int.
+const maxInt = int(^uint(0) >> 1)
+
+// chunk returns the largest write l allows at once.
+func (l *Limiter) chunk() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return maxInt
+	}
+
+	return int(l.burst)
+}
+
+// RateLimit limits the rate at which file content is copied to that of l.
+// Other writes, such as creating directories, are not limited.
+func RateLimit(l *Limiter) Option {
+	return func(o *options) {
+		o.limiter = l
+	}
+}
+
+// limitedWriter writes to w no faster than l allows.
+type limitedWriter struct {
+	w io.Writer
+	l *Limiter
+}
+
+func (lw limitedWriter) Write(p []byte) (int, error) {
+	var written int
+
+	for len(p) > 0 {
+		n := lw.l.chunk()
+		if n > len(p) {
+			n = len(p)
+		}
+
+		lw.l.wait(n)
+
+		n, err := lw.w.Write(p[:n])
+		written += n
+
+		if err != nil {
+			return written, err
+		}
+
+		p = p[n:]
+	}
+
+	return written, nil
+}
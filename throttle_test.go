@@ -0,0 +1,69 @@
+package copy
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func mustCreateSizedFile(t *testing.T, path string, size int) string {
+	t.Helper()
+
+	if err := ioutil.WriteFile(path, bytes.Repeat([]byte("x"), size), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestRateLimit(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "throttle")
+	src := mustCreateTestDirectory(t, d, "src")
+	mustCreateSizedFile(t, filepath.Join(src, "a"), 200)
+	mustCreateSizedFile(t, filepath.Join(src, "b"), 200)
+
+	// the first 100 bytes are the burst, the other 300 take 300ms
+	l := NewLimiter(1000, 100)
+	start := time.Now()
+
+	if err := All(src, filepath.Join(d, "dst"), RateLimit(l)); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("expected the copy to be throttled but it took %v", elapsed)
+	}
+
+	mustBeSameFile(t, filepath.Join(src, "a"), filepath.Join(d, "dst", "a"))
+	mustBeSameFile(t, filepath.Join(src, "b"), filepath.Join(d, "dst", "b"))
+}
+
+func TestRateLimitSetRate(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "throttle")
+	src := mustCreateSizedFile(t, filepath.Join(d, "src"), 1<<20)
+
+	l := NewLimiter(1, 1)
+	l.SetRate(0, 0)
+
+	start := time.Now()
+
+	if err := All(src, filepath.Join(d, "dst"), RateLimit(l)); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected an unlimited copy but it took %v", elapsed)
+	}
+}
+
+func TestLimiterBurst(t *testing.T) {
+	if burst := NewLimiter(500, 0).chunk(); burst != 500 {
+		t.Errorf("expected a default burst of one second but got %d", burst)
+	}
+
+	if burst := NewLimiter(0, 0).chunk(); burst != maxInt {
+		t.Errorf("expected unlimited writes without a rate but got %d", burst)
+	}
+}
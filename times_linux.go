@@ -0,0 +1,37 @@
+package copy
+
+import (
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// utimensat flags, which the syscall package does not export on Linux.
+const (
+	atFDCWD           = -0x64
+	atSymlinkNofollow = 0x100
+)
+
+// lchtimesOS sets the times of name with utimensat, which unlike
+// syscall.UtimesNano can leave a symbolic link unfollowed.
+func lchtimesOS(name string, atime, mtime time.Time) error {
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return &os.PathError{Op: "lchtimes", Path: name, Err: err}
+	}
+
+	ts := [2]syscall.Timespec{
+		syscall.NsecToTimespec(atime.UnixNano()),
+		syscall.NsecToTimespec(mtime.UnixNano()),
+	}
+	dirfd := atFDCWD
+
+	_, _, errno := syscall.Syscall6(syscall.SYS_UTIMENSAT, uintptr(dirfd), uintptr(unsafe.Pointer(p)),
+		uintptr(unsafe.Pointer(&ts[0])), atSymlinkNofollow, 0, 0)
+	if errno != 0 {
+		return &os.PathError{Op: "lchtimes", Path: name, Err: errno}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package copy
+
+import (
+	"io"
+	"os"
+	"reflect"
+)
+
+// TransformFunc returns the content to write for the file at the source
+// path, read from r. The returned reader may produce any number of bytes.
+// If it is an io.Closer it is closed once the file is written.
+type TransformFunc func(path string, r io.Reader) (io.Reader, error)
+
+type transform struct {
+	match func(path string, info os.FileInfo) bool
+	fn    TransformFunc
+}
+
+// Transform passes the content of each regular file for which match
+// returns true, or of every regular file if match is nil, through fn on
+// its way to the destination. Several Transform options are applied in
+// order, each reading the output of the one before it.
+//
+// Transformed files are always copied, never hard linked by LinkOrCopy,
+// and are copied again from the start instead of being resumed.
+func Transform(match func(path string, info os.FileInfo) bool, fn TransformFunc) Option {
+	return func(o *options) {
+		o.transforms = append(o.transforms, transform{match: match, fn: fn})
+	}
+}
+
+// TransformMode sets a function that returns the mode of each transformed
+// file from the path and mode of its source, for example to make a
+// generated script executable. ModeMask still applies.
+func TransformMode(fn func(path string, mode os.FileMode) os.FileMode) Option {
+	return func(o *options) {
+		o.transformMode = fn
+	}
+}
+
+// transformsFor returns the transforms that apply to the file f.
+func (o *options) transformsFor(f file) []TransformFunc {
+	var fns []TransformFunc
+
+	for _, t := range o.transforms {
+		if t.match == nil || t.match(f.path, f.info) {
+			fns = append(fns, t.fn)
+		}
+	}
+
+	return fns
+}
+
+// fileMode returns the mode of the copy of f, which is transformed by fns.
+func (o *options) fileMode(f file, fns []TransformFunc) os.FileMode {
+	mode := f.info.Mode()
+	if len(fns) > 0 && o.transformMode != nil {
+		mode = o.transformMode(f.path, mode)
+	}
+
+	return o.mode(mode)
+}
+
+// transformReader passes r, the content of the file at path, through fns.
+// The returned function closes every transformed reader.
+func transformReader(path string, r io.Reader, fns []TransformFunc) (io.Reader, func(), error) {
+	var closers []io.Closer
+
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closeFile(closers[i])
+		}
+	}
+
+	for _, fn := range fns {
+		tr, err := fn(path, r)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+
+		if c, ok := tr.(io.Closer); ok && !sameReader(tr, r) {
+			closers = append(closers, c)
+		}
+
+		r = tr
+	}
+
+	return r, closeAll, nil
+}
+
+// sameReader reports whether a and b are the same reader. Readers of types
+// that cannot be compared are never the same.
+func sameReader(a, b io.Reader) bool {
+	t := reflect.TypeOf(a)
+
+	return t != nil && t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
@@ -0,0 +1,117 @@
+package copy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func crlfToLF(_ string, r io.Reader) (io.Reader, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))), nil
+}
+
+func isText(path string, _ os.FileInfo) bool {
+	return strings.HasSuffix(path, ".txt")
+}
+
+func TestTransform(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "transform")
+	src := mustCreateTestDirectory(t, d, "src")
+	dst := filepath.Join(d, "dst")
+
+	if err := ioutil.WriteFile(filepath.Join(src, "a.txt"), []byte("one\r\ntwo\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "b.bin"), []byte("one\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	upper := func(_ string, r io.Reader) (io.Reader, error) {
+		b, err := ioutil.ReadAll(r)
+		return bytes.NewReader(bytes.ToUpper(b)), err
+	}
+
+	exec := func(_ string, mode os.FileMode) os.FileMode {
+		return mode | 0111
+	}
+
+	err := LinkOrCopy(src, dst, Transform(isText, crlfToLF), Transform(isText, upper), TransformMode(exec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := filepath.Join(dst, "a.txt")
+	if b := mustReadFile(t, a); string(b) != "ONE\nTWO\n" {
+		t.Errorf("expected transformed content but got %q", b)
+	}
+
+	fi := mustStat(t, a)
+	if fi.Mode() != 0755 {
+		t.Errorf("expected mode 0755 but got %v", fi.Mode())
+	}
+	if os.SameFile(fi, mustStat(t, filepath.Join(src, "a.txt"))) {
+		t.Error("expected transformed file not to be linked")
+	}
+
+	// files that are not matched are linked as usual
+	if !os.SameFile(mustStat(t, filepath.Join(src, "b.bin")), mustStat(t, filepath.Join(dst, "b.bin"))) {
+		t.Error("expected untransformed file to be linked")
+	}
+}
+
+func TestTransformError(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "transform")
+	src := mustCreateTestFile(t, filepath.Join(d, "src")).Name()
+	errTransform := errors.New("transform failed")
+
+	fail := func(string, io.Reader) (io.Reader, error) {
+		return nil, errTransform
+	}
+
+	err := All(src, filepath.Join(d, "dst"), Transform(nil, fail))
+
+	var e *Error
+	if !errors.As(err, &e) || e.Op != "Transform" || !errors.Is(err, errTransform) {
+		t.Errorf("expected Transform error but got %v", err)
+	}
+}
+
+// sliceReader is a reader whose type cannot be compared.
+type sliceReader struct {
+	io.Reader
+	closed *int
+	_      []byte
+}
+
+func (r sliceReader) Close() error {
+	*r.closed++
+	return nil
+}
+
+func TestTransformNotComparable(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "transform")
+	src := mustCreateTestFile(t, filepath.Join(d, "src")).Name()
+	dst := filepath.Join(d, "dst")
+
+	var closed int
+	wrap := func(_ string, r io.Reader) (io.Reader, error) {
+		return sliceReader{Reader: r, closed: &closed}, nil
+	}
+
+	if err := All(src, dst, Transform(nil, wrap), Transform(nil, wrap)); err != nil {
+		t.Fatal(err)
+	}
+	if closed != 2 {
+		t.Errorf("expected both transformed readers to be closed but %d were", closed)
+	}
+}
@@ -0,0 +1,95 @@
+package copy
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"io"
+)
+
+// FromTar extracts the tar stream r, which may be gzip-compressed, to dst.
+// Entries are written with the same rules as All: existing entries are
+// replaced, modes are restored, and the Filter, PreserveTimes,
+// ContinueOnError, special file policy and Destination options are
+// honored. Paths given to the Filter are the cleaned, slash-separated entry
+// names.
+//
+// Entries whose names are absolute or contain ".." elements, and entries
+// that would be written through a symlink created earlier, fail with
+// ErrUnsafePath instead of being written outside of dst. So do entries
+// other than directories that would replace dst itself.
+func FromTar(r io.Reader, dst string, opts ...Option) (err error) {
+	br := bufio.NewReader(r)
+
+	// gzip streams start with the magic bytes 1f 8b
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return &Error{Op: "gzip.NewReader", Dst: dst, Err: err}
+		}
+
+		defer closeFile(zr)
+
+		r = zr
+	} else {
+		r = br
+	}
+
+	x := &extractor{o: newOptions(false, opts), dst: dst}
+	defer func() { err = x.finish(err) }()
+
+	tr := tar.NewReader(r)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		// the stream cannot be read past a corrupt header
+		if err != nil {
+			return &Error{Op: "Next", Dst: dst, Err: err}
+		}
+
+		if err = x.done(extractTarEntry(x, hdr, tr)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractTarEntry writes the entry described by hdr, with content read from
+// r, and reports whether it was written or skipped.
+func extractTarEntry(x *extractor, hdr *tar.Header, r io.Reader) (bool, error) {
+	if hdr.Typeflag == tar.TypeXGlobalHeader {
+		return false, nil
+	}
+
+	rel, dst, err := x.target(hdr.Name)
+	if err != nil {
+		return false, err
+	}
+
+	info := hdr.FileInfo()
+	if x.skip(rel, info) {
+		return false, nil
+	}
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return true, x.dir(hdr.Name, dst, info)
+	case tar.TypeReg:
+		return true, x.file(hdr.Name, dst, info, r)
+	case tar.TypeSymlink:
+		return true, x.symlink(hdr.Name, dst, hdr.Linkname)
+	case tar.TypeLink:
+		return true, x.hardlink(hdr.Name, dst, hdr.Linkname)
+	case tar.TypeFifo:
+		return x.special(hdr.Name, dst, info, x.o.fifos)
+	case tar.TypeChar, tar.TypeBlock:
+		return x.special(hdr.Name, dst, info, x.o.devices)
+	default:
+		return false, &Error{Op: "FromTar", Src: hdr.Name, Dst: dst, Err: unsupportedType(info)}
+	}
+}
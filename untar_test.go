@@ -0,0 +1,182 @@
+package copy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func mustWriteTar(t *testing.T, headers ...*tar.Header) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+
+	for _, hdr := range headers {
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len(hdr.Name))
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(hdr.Name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf
+}
+
+func TestFromTar(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "fromtar")
+	src := mustCreateTestDirectory(t, d, "src")
+	sub := mustMkdirAll(t, filepath.Join(src, "sub"))
+	mustCreateTestFile(t, filepath.Join(sub, "file1"))
+	mustCreateTestLink(t, filepath.Join(src, "link1"), "sub/file1")
+
+	if err := os.Link(filepath.Join(sub, "file1"), filepath.Join(src, "hardlink")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chmod(sub, 0500); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := ToTar(src, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(d, "dst")
+	if err := FromTar(&buf, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	mustBeSameFile(t, src, dst)
+	mustBeSameFile(t, sub, filepath.Join(dst, "sub"))
+	mustBeSameFile(t, filepath.Join(sub, "file1"), filepath.Join(dst, "sub", "file1"))
+	mustBeSameFile(t, filepath.Join(src, "link1"), filepath.Join(dst, "link1"))
+	mustBeSameFile(t, filepath.Join(src, "hardlink"), filepath.Join(dst, "hardlink"))
+}
+
+func TestFromTarGzip(t *testing.T) {
+	tb := mustWriteTar(t, &tar.Header{Name: "file1", Typeflag: tar.TypeReg, Mode: 0644})
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(tb.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := mustCreateTestDirectory(t, "", "fromtar")
+	if err := FromTar(&buf, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dst, "file1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "file1" {
+		t.Errorf("expected 'file1' but got '%s'", b)
+	}
+}
+
+func TestFromTarFilter(t *testing.T) {
+	tb := mustWriteTar(t,
+		&tar.Header{Name: "skip/", Typeflag: tar.TypeDir, Mode: 0755},
+		&tar.Header{Name: "skip/file1", Typeflag: tar.TypeReg, Mode: 0644},
+		&tar.Header{Name: "keep", Typeflag: tar.TypeReg, Mode: 0644},
+	)
+
+	exclude := func(path string, _ os.FileInfo) bool {
+		return path != "skip"
+	}
+
+	dst := mustCreateTestDirectory(t, "", "fromtar")
+	if err := FromTar(tb, dst, Filter(exclude)); err != nil {
+		t.Fatal(err)
+	}
+
+	mustExist(t, filepath.Join(dst, "keep"))
+	mustNotExist(t, filepath.Join(dst, "skip"))
+}
+
+func TestFromTarUnsafe(t *testing.T) {
+	outside := mustCreateTestDirectory(t, "", "outside")
+
+	testCases := []struct {
+		name    string
+		headers []*tar.Header
+	}{
+		{"parent", []*tar.Header{{Name: "../escape", Typeflag: tar.TypeReg, Mode: 0644}}},
+		{"nested parent", []*tar.Header{{Name: "a/../../escape", Typeflag: tar.TypeReg, Mode: 0644}}},
+		{"absolute", []*tar.Header{{Name: filepath.Join(outside, "escape"), Typeflag: tar.TypeReg, Mode: 0644}}},
+		{"symlink then write", []*tar.Header{
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: outside},
+			{Name: "link/escape", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+		{"hardlink to parent", []*tar.Header{{Name: "hardlink", Typeflag: tar.TypeLink, Linkname: "../escape"}}},
+		{"file at root", []*tar.Header{{Name: ".", Typeflag: tar.TypeReg, Mode: 0644}}},
+		{"symlink at root", []*tar.Header{{Name: "a/..", Typeflag: tar.TypeSymlink, Linkname: outside}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dst := mustCreateTestDirectory(t, "", "fromtar")
+			err := FromTar(mustWriteTar(t, tc.headers...), dst)
+			if !errors.Is(err, ErrUnsafePath) {
+				t.Errorf("expected ErrUnsafePath but got %v", err)
+			}
+			mustNotExist(t, filepath.Join(outside, "escape"))
+
+			if fi, err := os.Lstat(dst); err != nil || !fi.IsDir() {
+				t.Errorf("expected %s to remain a directory", dst)
+			}
+		})
+	}
+}
+
+func TestFromTarRestoresModesOnError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directory modes are not restored on windows")
+	}
+
+	dst := mustCreateTestDirectory(t, "", "fromtar")
+	tarball := mustWriteTar(t,
+		&tar.Header{Name: "ro/", Typeflag: tar.TypeDir, Mode: 0555},
+		&tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Mode: 0644},
+	)
+
+	if err := FromTar(tarball, dst); !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath but got %v", err)
+	}
+
+	ro := filepath.Join(dst, "ro")
+	t.Cleanup(func() { _ = os.Chmod(ro, 0755) })
+
+	if mode := mustStat(t, ro).Mode().Perm(); mode != 0555 {
+		t.Errorf("expected mode 0555 but got %v", mode)
+	}
+}
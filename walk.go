@@ -0,0 +1,77 @@
+package copy
+
+import (
+	"io/fs"
+)
+
+// WalkFunc is the type of the function called by Walk for each object.
+//
+// If the object at path cannot be read, obj is nil and err describes the
+// failure. If a directory can be read but its children cannot, fn is called
+// a second time for the directory with the error. Returning fs.SkipDir for
+// a directory skips its children; returning it for any other object skips
+// the remaining objects in its parent. Any other non-nil error stops the
+// walk and is returned by Walk.
+type WalkFunc func(path string, obj Object, err error) error
+
+// Walk calls fn for root and every object below it, in the order All
+// copies them: each directory before its children and the children of a
+// directory in lexical order. Symlinks are not followed. Entries rejected
+// by a Filter option are skipped along with everything below them.
+func Walk(root string, fn WalkFunc, opts ...Option) error {
+	o := newOptions(false, opts)
+
+	obj, err := newObjectFS(o.fsys, root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walk(obj, fn, o)
+	}
+
+	if err == fs.SkipDir {
+		return nil
+	}
+
+	return err
+}
+
+// walk calls fn for obj and, if it is a directory, everything below it.
+func walk(obj copyObject, fn WalkFunc, o *options) error {
+	if err := fn(obj.Path(), obj, nil); err != nil || obj.Kind() != KindDirectory {
+		return err
+	}
+
+	children, err := readDir(o.fsys, obj.Path())
+	if err != nil {
+		return fn(obj.Path(), obj, &Error{Op: "ReadDir", Src: obj.Path(), Err: err})
+	}
+
+	for _, child := range children {
+		childPath := join(o.fsys, obj.Path(), child.Name())
+
+		if !o.included(childPath, child) {
+			continue
+		}
+
+		childObj, err := newObjectFS(o.fsys, childPath)
+		if err != nil {
+			err = fn(childPath, nil, err)
+		} else {
+			err = walk(childObj, fn, o)
+		}
+
+		if err == fs.SkipDir {
+			if child.IsDir() {
+				continue
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package copy
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func mustWalk(t *testing.T, root string, fn WalkFunc, opts ...Option) []string {
+	t.Helper()
+
+	var visited []string
+
+	err := Walk(root, func(path string, obj Object, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		visited = append(visited, filepath.ToSlash(rel)+":"+obj.Kind().String())
+
+		if fn != nil {
+			return fn(path, obj, nil)
+		}
+
+		return nil
+	}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return visited
+}
+
+func TestWalk(t *testing.T) {
+	src := mustCreateTestTree(t, mustCreateTestDirectory(t, "", "walk"))
+
+	expected := []string{".:directory", "file2:file", "link1:symlink", "sub:directory", "sub/file1:file"}
+	if visited := mustWalk(t, src, nil); !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %v but got %v", expected, visited)
+	}
+}
+
+func TestWalkSkip(t *testing.T) {
+	src := mustCreateTestTree(t, mustCreateTestDirectory(t, "", "walk"))
+
+	skipSub := func(path string, obj Object, _ error) error {
+		if obj.Info().Name() == "sub" {
+			return fs.SkipDir
+		}
+
+		return nil
+	}
+
+	expected := []string{".:directory", "file2:file", "link1:symlink", "sub:directory"}
+	if visited := mustWalk(t, src, skipSub); !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %v but got %v", expected, visited)
+	}
+
+	skipAfterFile := func(path string, obj Object, _ error) error {
+		if obj.Info().Name() == "file2" {
+			return fs.SkipDir
+		}
+
+		return nil
+	}
+
+	expected = []string{".:directory", "file2:file"}
+	if visited := mustWalk(t, src, skipAfterFile); !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %v but got %v", expected, visited)
+	}
+}
+
+func TestWalkFilter(t *testing.T) {
+	src := mustCreateTestTree(t, mustCreateTestDirectory(t, "", "walk"))
+
+	noSymlinks := func(_ string, info os.FileInfo) bool {
+		return info.Mode()&os.ModeSymlink == 0
+	}
+
+	expected := []string{".:directory", "file2:file", "sub:directory", "sub/file1:file"}
+	if visited := mustWalk(t, src, nil, Filter(noSymlinks)); !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %v but got %v", expected, visited)
+	}
+}
+
+func TestWalkError(t *testing.T) {
+	errStop := errors.New("stop")
+
+	var called bool
+
+	err := Walk("/does/not/exist", func(path string, obj Object, err error) error {
+		called = true
+		if obj != nil || err == nil {
+			t.Errorf("expected an error and no object but got %v, %v", obj, err)
+		}
+
+		return errStop
+	})
+	if !called || !errors.Is(err, errStop) {
+		t.Errorf("expected the callback error but got %v", err)
+	}
+}
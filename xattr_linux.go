@@ -0,0 +1,50 @@
+package copy
+
+import (
+	"bytes"
+	"syscall"
+)
+
+// xattrs returns the extended attributes of the file at path.
+func xattrs(path string) (map[string]string, error) {
+	size, err := syscall.Listxattr(path, nil)
+	if err != nil || size == 0 {
+		return nil, ignoreXattrErr(err)
+	}
+
+	buf := make([]byte, size)
+	if size, err = syscall.Listxattr(path, buf); err != nil {
+		return nil, ignoreXattrErr(err)
+	}
+
+	attrs := make(map[string]string)
+
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+
+		vsize, err := syscall.Getxattr(path, string(name), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		value := make([]byte, vsize)
+		if vsize, err = syscall.Getxattr(path, string(name), value); err != nil {
+			return nil, err
+		}
+
+		attrs[string(name)] = string(value[:vsize])
+	}
+
+	return attrs, nil
+}
+
+// ignoreXattrErr drops errors from file systems without xattr support.
+func ignoreXattrErr(err error) error {
+	if err == syscall.ENOTSUP {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package copy
+
+// xattrs is not supported on this platform.
+func xattrs(string) (map[string]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,162 @@
+package copy
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// maxZipLink is the longest symlink target read from a zip archive.
+const maxZipLink = 4096
+
+// CompressionLevel sets the flate compression level used by ToZip, from
+// flate.NoCompression to flate.BestCompression. With flate.NoCompression
+// entries are stored uncompressed. The default is flate.DefaultCompression.
+func CompressionLevel(level int) Option {
+	return func(o *options) {
+		o.compression = level
+	}
+}
+
+// ToZip writes src and everything below it to w as a zip archive. Entry
+// names are relative to src, or the base name of src when it is not a
+// directory, and are written in lexical order so the same tree always
+// produces the same entry order. Symlinks are stored with their target as
+// content and the Unix mode in the external attributes. Zip has no hard
+// links, so every name of a hard linked file is stored with its content.
+//
+// ToZip honors the Filter, ContinueOnError, special file policy,
+// CompressionLevel, ModTime, ModeMask and Reproducible options.
+func ToZip(src string, w io.Writer, opts ...Option) error {
+	o := newOptions(false, opts)
+	zw := zip.NewWriter(w)
+
+	zw.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, o.compression)
+	})
+
+	return writeArchive(src, &zipWriter{zw: zw}, o)
+}
+
+// zipWriter is an archiver that writes a zip archive.
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipWriter) writeEntry(e *archiveEntry) error {
+	// the root directory has no entry of its own
+	if e.name == "." {
+		return nil
+	}
+
+	hdr, err := zip.FileInfoHeader(e.obj.Info())
+	if err != nil {
+		return &Error{Op: "FileInfoHeader", Src: e.obj.Path(), Err: err}
+	}
+
+	hdr.Name = e.name
+	hdr.SetMode(e.o.mode(e.obj.Info().Mode()))
+
+	// DOS times are in local time, so record a fixed time in UTC
+	if !e.o.modTime.IsZero() {
+		hdr.Modified = e.o.modTime.UTC()
+	}
+
+	if _, ok := e.obj.(file); !ok || e.o.compression == flate.NoCompression {
+		hdr.Method = zip.Store
+	} else {
+		hdr.Method = zip.Deflate
+	}
+
+	if _, ok := e.obj.(directory); ok {
+		hdr.Name += "/"
+	}
+
+	w, err := z.zw.CreateHeader(hdr)
+	if err != nil {
+		return &Error{Op: "CreateHeader", Src: e.obj.Path(), Dst: hdr.Name, Err: err}
+	}
+
+	switch e.obj.(type) {
+	case file:
+		return e.writeContent(w)
+	case link:
+		_, err = io.WriteString(w, e.target)
+		return wrapError("Write", e.obj.Path(), hdr.Name, err)
+	}
+
+	return nil
+}
+
+func (z *zipWriter) close() error {
+	return z.zw.Close()
+}
+
+// FromZip extracts the zip archive r of the given size to dst with the same
+// rules and options as FromTar. Entries whose names would escape dst fail
+// with ErrUnsafePath. Device nodes cannot be recreated from a zip archive
+// since it does not record device numbers.
+func FromZip(r io.ReaderAt, size int64, dst string, opts ...Option) (err error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return &Error{Op: "zip.NewReader", Dst: dst, Err: err}
+	}
+
+	x := &extractor{o: newOptions(false, opts), dst: dst}
+	defer func() { err = x.finish(err) }()
+
+	for _, f := range zr.File {
+		if err = x.done(extractZipEntry(x, f)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractZipEntry writes the zip entry f and reports whether it was written
+// or skipped.
+func extractZipEntry(x *extractor, f *zip.File) (bool, error) {
+	rel, dst, err := x.target(f.Name)
+	if err != nil {
+		return false, err
+	}
+
+	info := f.FileInfo()
+	if x.skip(rel, info) {
+		return false, nil
+	}
+
+	mode := info.Mode()
+
+	switch {
+	case mode.IsDir():
+		return true, x.dir(f.Name, dst, info)
+	case mode&os.ModeDevice != 0:
+		return false, &Error{Op: "FromZip", Src: f.Name, Dst: dst, Err: unsupportedType(info)}
+	case mode&os.ModeNamedPipe != 0:
+		return x.special(f.Name, dst, info, x.o.fifos)
+	case mode&os.ModeSocket != 0:
+		return x.special(f.Name, dst, info, x.o.sockets)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return false, &Error{Op: "Open", Src: f.Name, Dst: dst, Err: err}
+	}
+
+	defer closeFile(rc)
+
+	if mode&os.ModeSymlink != 0 {
+		target, err := ioutil.ReadAll(io.LimitReader(rc, maxZipLink))
+		if err != nil {
+			return false, &Error{Op: "Read", Src: f.Name, Dst: dst, Err: err}
+		}
+
+		return true, x.symlink(f.Name, dst, string(target))
+	}
+
+	return true, x.file(f.Name, dst, info, rc)
+}
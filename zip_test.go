@@ -0,0 +1,100 @@
+package copy
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func mustCreateTestTree(t *testing.T, parent string) string {
+	t.Helper()
+
+	src := mustCreateTestDirectory(t, parent, "src")
+	sub := mustMkdirAll(t, filepath.Join(src, "sub"))
+	mustCreateTestFile(t, filepath.Join(sub, "file1"))
+	mustCreateTestFile(t, filepath.Join(src, "file2"))
+	mustCreateTestLink(t, filepath.Join(src, "link1"), "sub/file1")
+
+	return src
+}
+
+func TestZip(t *testing.T) {
+	d := mustCreateTestDirectory(t, "", "zip")
+	src := mustCreateTestTree(t, d)
+
+	var buf bytes.Buffer
+	if err := ToZip(src, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+
+	expected := []string{"file2", "link1", "sub/", "sub/file1"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected entries %v but got %v", expected, names)
+	}
+
+	dst := filepath.Join(d, "dst")
+	if err = FromZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()), dst); err != nil {
+		t.Fatal(err)
+	}
+
+	mustBeSameFile(t, filepath.Join(src, "sub"), filepath.Join(dst, "sub"))
+	mustBeSameFile(t, filepath.Join(src, "sub", "file1"), filepath.Join(dst, "sub", "file1"))
+	mustBeSameFile(t, filepath.Join(src, "file2"), filepath.Join(dst, "file2"))
+	mustBeSameFile(t, filepath.Join(src, "link1"), filepath.Join(dst, "link1"))
+}
+
+func TestZipCompressionLevel(t *testing.T) {
+	src := mustCreateTestTree(t, mustCreateTestDirectory(t, "", "zip"))
+
+	var buf bytes.Buffer
+	if err := ToZip(src, &buf, CompressionLevel(flate.NoCompression)); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range zr.File {
+		if f.Method != zip.Store {
+			t.Errorf("expected %s to be stored but got method %d", f.Name, f.Method)
+		}
+	}
+}
+
+func TestFromZipUnsafe(t *testing.T) {
+	for _, name := range []string{"../escape", "/escape", `..\escape`} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			zw := zip.NewWriter(&buf)
+			if _, err := zw.Create(name); err != nil {
+				t.Fatal(err)
+			}
+			if err := zw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			d := mustCreateTestDirectory(t, "", "zip")
+			err := FromZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()), filepath.Join(d, "dst"))
+			if !errors.Is(err, ErrUnsafePath) {
+				t.Errorf("expected ErrUnsafePath but got %v", err)
+			}
+			mustNotExist(t, filepath.Join(d, "escape"))
+		})
+	}
+}